@@ -0,0 +1,278 @@
+package elastic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Bucket is an aggregation bucket.
+type Bucket struct {
+	Key          interface{}  // Key such as a term, or a map for composite buckets, with numbers as json.Number
+	KeyAsString  string       // Formatted key, for example of dates
+	DocCount     int64        // Number of documents
+	From         *float64     // Range lower bound
+	To           *float64     // Range upper bound
+	FromAsString string       // Formatted range lower bound
+	ToAsString   string       // Formatted range upper bound
+	Aggs         Aggregations // Sub-aggregations
+}
+
+// UnmarshalJSON implementation.
+func (b *Bucket) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	for k, v := range fields {
+		var err error
+
+		switch k {
+		case "key":
+			err = unmarshalNumbers(v, &b.Key)
+		case "key_as_string":
+			err = json.Unmarshal(v, &b.KeyAsString)
+		case "doc_count":
+			err = json.Unmarshal(v, &b.DocCount)
+		case "from":
+			err = json.Unmarshal(v, &b.From)
+		case "to":
+			err = json.Unmarshal(v, &b.To)
+		case "from_as_string":
+			err = json.Unmarshal(v, &b.FromAsString)
+		case "to_as_string":
+			err = json.Unmarshal(v, &b.ToAsString)
+		default:
+			if len(v) > 0 && v[0] == '{' {
+				if b.Aggs == nil {
+					b.Aggs = make(Aggregations)
+				}
+				b.Aggs[k] = v
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BucketsResult is the result of a multi-bucket aggregation.
+type BucketsResult struct {
+	Buckets                 []*Bucket              // Buckets
+	DocCountErrorUpperBound int64                  // Terms count error upper bound
+	SumOtherDocCount        int64                  // Terms count of documents not in a bucket
+	AfterKey                map[string]interface{} // Composite key to page after, with numbers as json.Number
+}
+
+// UnmarshalJSON implementation, accepting keyed buckets as well as arrays.
+// Keyed buckets are in the order of the response.
+func (r *BucketsResult) UnmarshalJSON(data []byte) error {
+	var res struct {
+		Buckets                 json.RawMessage `json:"buckets"`
+		DocCountErrorUpperBound int64           `json:"doc_count_error_upper_bound"`
+		SumOtherDocCount        int64           `json:"sum_other_doc_count"`
+		AfterKey                json.RawMessage `json:"after_key"`
+	}
+
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+
+	r.DocCountErrorUpperBound = res.DocCountErrorUpperBound
+	r.SumOtherDocCount = res.SumOtherDocCount
+
+	if len(res.AfterKey) > 0 {
+		if err := unmarshalNumbers(res.AfterKey, &r.AfterKey); err != nil {
+			return err
+		}
+	}
+
+	if len(res.Buckets) == 0 || res.Buckets[0] != '{' {
+		return json.Unmarshal(res.Buckets, &r.Buckets)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(res.Buckets))
+
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		b := new(Bucket)
+		if err := dec.Decode(b); err != nil {
+			return err
+		}
+
+		if b.Key == nil {
+			b.Key = t.(string)
+		}
+
+		r.Buckets = append(r.Buckets, b)
+	}
+
+	return nil
+}
+
+// unmarshalNumbers unmarshals `data` into `v`, decoding numbers as
+// json.Number so that longs keep their precision.
+func unmarshalNumbers(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// ValueMetric is the result of a single-value metric aggregation.
+type ValueMetric struct {
+	Value         *float64 `json:"value"`
+	ValueAsString string   `json:"value_as_string"`
+}
+
+// StatsMetric is the result of a stats aggregation.
+type StatsMetric struct {
+	Count int64    `json:"count"`
+	Min   *float64 `json:"min"`
+	Max   *float64 `json:"max"`
+	Avg   *float64 `json:"avg"`
+	Sum   float64  `json:"sum"`
+}
+
+// PercentilesMetric is the result of a percentiles aggregation.
+type PercentilesMetric struct {
+	Values map[string]*float64 `json:"values"`
+}
+
+// TopHitsResult is the result of a top_hits aggregation.
+type TopHitsResult struct {
+	Hits SearchHits `json:"hits"`
+}
+
+// Decode aggregation `name` into `v`, returning an error when it is missing
+// or invalid. The typed accessors such as Terms return false in both cases.
+func (a Aggregations) Decode(name string, v interface{}) error {
+	raw, ok := a[name]
+	if !ok {
+		return fmt.Errorf("elastic: aggregation %q not found", name)
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("elastic: decoding aggregation %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// decode aggregation `name` into `v`, returning false when missing or invalid.
+func (a Aggregations) decode(name string, v interface{}) bool {
+	return a.Decode(name, v) == nil
+}
+
+// Buckets returns multi-bucket aggregation `name`.
+func (a Aggregations) Buckets(name string) (*BucketsResult, bool) {
+	v := new(BucketsResult)
+	return v, a.decode(name, v)
+}
+
+// Terms returns terms aggregation `name`.
+func (a Aggregations) Terms(name string) (*BucketsResult, bool) {
+	return a.Buckets(name)
+}
+
+// Histogram returns histogram aggregation `name`.
+func (a Aggregations) Histogram(name string) (*BucketsResult, bool) {
+	return a.Buckets(name)
+}
+
+// DateHistogram returns date_histogram aggregation `name`.
+func (a Aggregations) DateHistogram(name string) (*BucketsResult, bool) {
+	return a.Buckets(name)
+}
+
+// Range returns range aggregation `name`.
+func (a Aggregations) Range(name string) (*BucketsResult, bool) {
+	return a.Buckets(name)
+}
+
+// DateRange returns date_range aggregation `name`.
+func (a Aggregations) DateRange(name string) (*BucketsResult, bool) {
+	return a.Buckets(name)
+}
+
+// Filters returns filters aggregation `name`.
+func (a Aggregations) Filters(name string) (*BucketsResult, bool) {
+	return a.Buckets(name)
+}
+
+// Composite returns composite aggregation `name`.
+func (a Aggregations) Composite(name string) (*BucketsResult, bool) {
+	return a.Buckets(name)
+}
+
+// Filter returns single bucket filter aggregation `name`.
+func (a Aggregations) Filter(name string) (*Bucket, bool) {
+	v := new(Bucket)
+	return v, a.decode(name, v)
+}
+
+// Nested returns single bucket nested aggregation `name`.
+func (a Aggregations) Nested(name string) (*Bucket, bool) {
+	v := new(Bucket)
+	return v, a.decode(name, v)
+}
+
+// Value returns single-value metric aggregation `name`.
+func (a Aggregations) Value(name string) (*ValueMetric, bool) {
+	v := new(ValueMetric)
+	return v, a.decode(name, v)
+}
+
+// Avg returns avg aggregation `name`.
+func (a Aggregations) Avg(name string) (*ValueMetric, bool) {
+	return a.Value(name)
+}
+
+// Sum returns sum aggregation `name`.
+func (a Aggregations) Sum(name string) (*ValueMetric, bool) {
+	return a.Value(name)
+}
+
+// Min returns min aggregation `name`.
+func (a Aggregations) Min(name string) (*ValueMetric, bool) {
+	return a.Value(name)
+}
+
+// Max returns max aggregation `name`.
+func (a Aggregations) Max(name string) (*ValueMetric, bool) {
+	return a.Value(name)
+}
+
+// Cardinality returns cardinality aggregation `name`.
+func (a Aggregations) Cardinality(name string) (*ValueMetric, bool) {
+	return a.Value(name)
+}
+
+// Stats returns stats aggregation `name`.
+func (a Aggregations) Stats(name string) (*StatsMetric, bool) {
+	v := new(StatsMetric)
+	return v, a.decode(name, v)
+}
+
+// Percentiles returns percentiles aggregation `name`.
+func (a Aggregations) Percentiles(name string) (*PercentilesMetric, bool) {
+	v := new(PercentilesMetric)
+	return v, a.decode(name, v)
+}
+
+// TopHits returns top_hits aggregation `name`.
+func (a Aggregations) TopHits(name string) (*TopHitsResult, bool) {
+	v := new(TopHitsResult)
+	return v, a.decode(name, v)
+}
@@ -0,0 +1,126 @@
+package elastic
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var aggregations = `{
+  "species": {
+    "doc_count_error_upper_bound": 0,
+    "sum_other_doc_count": 0,
+    "buckets": [
+      { "key": "ferret", "doc_count": 3, "age": { "value": 2.5 } },
+      { "key": "cat", "doc_count": 2, "age": { "value": null } }
+    ]
+  },
+  "born": {
+    "buckets": [
+      { "key_as_string": "2016-01-01", "key": 1451606400000, "doc_count": 4 }
+    ]
+  },
+  "sizes": {
+    "buckets": {
+      "small": { "doc_count": 3 },
+      "large": { "doc_count": 2 }
+    }
+  },
+  "ages": {
+    "buckets": [
+      { "key": "*-2.0", "to": 2.0, "doc_count": 1 },
+      { "key": "2.0-*", "from": 2.0, "doc_count": 4 }
+    ]
+  },
+  "owners": {
+    "after_key": { "owner": "tj" },
+    "buckets": [{ "key": { "owner": "tj" }, "doc_count": 5 }]
+  },
+  "toys": { "doc_count": 7, "kinds": { "value": 3 } },
+  "weight": { "count": 5, "min": 1, "max": 10, "avg": 4, "sum": 20 },
+  "latency": { "values": { "50.0": 12.5, "99.0": 80 } },
+  "top": { "hits": { "total": 5, "hits": [{ "_index": "pets", "_id": "1", "_source": { "name": "Tobi" } }] } }
+}`
+
+func TestAggregations(t *testing.T) {
+	var aggs Aggregations
+	assert.NoError(t, json.Unmarshal([]byte(aggregations), &aggs))
+
+	species, ok := aggs.Terms("species")
+	assert.True(t, ok, "species")
+	assert.Len(t, species.Buckets, 2)
+	assert.Equal(t, "ferret", species.Buckets[0].Key)
+	assert.Equal(t, int64(3), species.Buckets[0].DocCount)
+
+	age, ok := species.Buckets[0].Aggs.Avg("age")
+	assert.True(t, ok, "age")
+	assert.Equal(t, 2.5, *age.Value)
+
+	age, ok = species.Buckets[1].Aggs.Avg("age")
+	assert.True(t, ok, "age")
+	assert.Nil(t, age.Value)
+
+	born, ok := aggs.DateHistogram("born")
+	assert.True(t, ok, "born")
+	assert.Equal(t, "2016-01-01", born.Buckets[0].KeyAsString)
+	assert.Equal(t, json.Number("1451606400000"), born.Buckets[0].Key)
+
+	sizes, ok := aggs.Filters("sizes")
+	assert.True(t, ok, "sizes")
+	assert.Equal(t, "small", sizes.Buckets[0].Key)
+	assert.Equal(t, int64(3), sizes.Buckets[0].DocCount)
+	assert.Equal(t, "large", sizes.Buckets[1].Key)
+
+	ages, ok := aggs.Range("ages")
+	assert.True(t, ok, "ages")
+	assert.Nil(t, ages.Buckets[0].From)
+	assert.Equal(t, 2.0, *ages.Buckets[0].To)
+
+	owners, ok := aggs.Composite("owners")
+	assert.True(t, ok, "owners")
+	assert.Equal(t, map[string]interface{}{"owner": "tj"}, owners.AfterKey)
+	assert.Equal(t, map[string]interface{}{"owner": "tj"}, owners.Buckets[0].Key)
+
+	toys, ok := aggs.Nested("toys")
+	assert.True(t, ok, "toys")
+	assert.Equal(t, int64(7), toys.DocCount)
+	kinds, _ := toys.Aggs.Cardinality("kinds")
+	assert.Equal(t, 3.0, *kinds.Value)
+
+	weight, ok := aggs.Stats("weight")
+	assert.True(t, ok, "weight")
+	assert.Equal(t, int64(5), weight.Count)
+	assert.Equal(t, 20.0, weight.Sum)
+
+	latency, ok := aggs.Percentiles("latency")
+	assert.True(t, ok, "latency")
+	assert.Equal(t, 80.0, *latency.Values["99.0"])
+
+	top, ok := aggs.TopHits("top")
+	assert.True(t, ok, "top")
+	assert.Equal(t, int64(5), top.Hits.Total.Value)
+
+	_, ok = aggs.Terms("missing")
+	assert.False(t, ok, "missing")
+}
+
+func TestAggregations_Decode(t *testing.T) {
+	aggs := Aggregations{"weight": json.RawMessage(`{ "count": "five" }`)}
+
+	_, ok := aggs.Stats("weight")
+	assert.False(t, ok, "invalid")
+
+	var v StatsMetric
+	assert.Error(t, aggs.Decode("weight", &v))
+	assert.EqualError(t, aggs.Decode("missing", &v), `elastic: aggregation "missing" not found`)
+}
+
+func TestBucket_longKey(t *testing.T) {
+	aggs := Aggregations{"ids": json.RawMessage(`{ "after_key": { "id": 9007199254740993 }, "buckets": [{ "key": 9007199254740993, "doc_count": 1 }] }`)}
+
+	ids, ok := aggs.Terms("ids")
+	assert.True(t, ok, "ids")
+	assert.Equal(t, json.Number("9007199254740993"), ids.Buckets[0].Key)
+	assert.Equal(t, map[string]interface{}{"id": json.Number("9007199254740993")}, ids.AfterKey)
+}
@@ -0,0 +1,419 @@
+// Package aggs provides builders for Elasticsearch aggregations. Aggregations
+// marshal to their JSON representation, and may be added to a search request
+// body with query.SearchSource.Aggregation.
+package aggs
+
+import (
+	"encoding/json"
+
+	"github.com/tj/go-elastic/query"
+)
+
+// Aggregation is an aggregation.
+type Aggregation interface {
+	json.Marshaler
+}
+
+// params of an aggregation.
+type params map[string]interface{}
+
+// agg is an aggregation of the form {kind: params, "aggs": {...}}.
+type agg struct {
+	kind   string
+	params params
+	aggs   map[string]Aggregation
+}
+
+// newAgg returns an aggregation of `kind`.
+func newAgg(kind string, p params) agg {
+	return agg{kind: kind, params: p}
+}
+
+// sub adds sub-aggregation `a` as `name`.
+func (a *agg) sub(name string, v Aggregation) {
+	if a.aggs == nil {
+		a.aggs = make(map[string]Aggregation)
+	}
+	a.aggs[name] = v
+}
+
+// MarshalJSON implementation.
+func (a agg) MarshalJSON() ([]byte, error) {
+	p := params{a.kind: a.params}
+
+	if len(a.aggs) > 0 {
+		p["aggs"] = a.aggs
+	}
+
+	return json.Marshal(p)
+}
+
+// TermsAggregation buckets by term.
+type TermsAggregation struct{ agg }
+
+// Terms returns a terms aggregation of `field`.
+func Terms(field string) *TermsAggregation {
+	return &TermsAggregation{newAgg("terms", params{"field": field})}
+}
+
+// Size sets the number of buckets.
+func (a *TermsAggregation) Size(v int) *TermsAggregation {
+	a.params["size"] = v
+	return a
+}
+
+// MinDocCount sets the minimum document count of buckets.
+func (a *TermsAggregation) MinDocCount(v int) *TermsAggregation {
+	a.params["min_doc_count"] = v
+	return a
+}
+
+// Order sets the bucket order such as ("_count", "desc").
+func (a *TermsAggregation) Order(key, order string) *TermsAggregation {
+	a.params["order"] = params{key: order}
+	return a
+}
+
+// Missing sets the term of documents without a value.
+func (a *TermsAggregation) Missing(v interface{}) *TermsAggregation {
+	a.params["missing"] = v
+	return a
+}
+
+// Aggregation adds sub-aggregation `v` as `name`.
+func (a *TermsAggregation) Aggregation(name string, v Aggregation) *TermsAggregation {
+	a.sub(name, v)
+	return a
+}
+
+// HistogramAggregation buckets by numeric interval.
+type HistogramAggregation struct{ agg }
+
+// Histogram returns a histogram of `field` with `interval`.
+func Histogram(field string, interval float64) *HistogramAggregation {
+	return &HistogramAggregation{newAgg("histogram", params{
+		"field":    field,
+		"interval": interval,
+	})}
+}
+
+// MinDocCount sets the minimum document count of buckets.
+func (a *HistogramAggregation) MinDocCount(v int) *HistogramAggregation {
+	a.params["min_doc_count"] = v
+	return a
+}
+
+// ExtendedBounds sets the bounds buckets are created for.
+func (a *HistogramAggregation) ExtendedBounds(min, max float64) *HistogramAggregation {
+	a.params["extended_bounds"] = params{"min": min, "max": max}
+	return a
+}
+
+// Aggregation adds sub-aggregation `v` as `name`.
+func (a *HistogramAggregation) Aggregation(name string, v Aggregation) *HistogramAggregation {
+	a.sub(name, v)
+	return a
+}
+
+// DateHistogramAggregation buckets by date interval.
+type DateHistogramAggregation struct{ agg }
+
+// DateHistogram returns a date histogram of `field`.
+func DateHistogram(field string) *DateHistogramAggregation {
+	return &DateHistogramAggregation{newAgg("date_histogram", params{"field": field})}
+}
+
+// CalendarInterval sets the calendar interval such as "1d" or "month".
+func (a *DateHistogramAggregation) CalendarInterval(v string) *DateHistogramAggregation {
+	a.params["calendar_interval"] = v
+	return a
+}
+
+// FixedInterval sets the fixed interval such as "30m".
+func (a *DateHistogramAggregation) FixedInterval(v string) *DateHistogramAggregation {
+	a.params["fixed_interval"] = v
+	return a
+}
+
+// Interval sets the interval for clusters prior to Elasticsearch 7.2.
+func (a *DateHistogramAggregation) Interval(v string) *DateHistogramAggregation {
+	a.params["interval"] = v
+	return a
+}
+
+// Format sets the key format.
+func (a *DateHistogramAggregation) Format(v string) *DateHistogramAggregation {
+	a.params["format"] = v
+	return a
+}
+
+// TimeZone sets the time zone such as "America/Vancouver".
+func (a *DateHistogramAggregation) TimeZone(v string) *DateHistogramAggregation {
+	a.params["time_zone"] = v
+	return a
+}
+
+// MinDocCount sets the minimum document count of buckets.
+func (a *DateHistogramAggregation) MinDocCount(v int) *DateHistogramAggregation {
+	a.params["min_doc_count"] = v
+	return a
+}
+
+// ExtendedBounds sets the bounds buckets are created for.
+func (a *DateHistogramAggregation) ExtendedBounds(min, max interface{}) *DateHistogramAggregation {
+	a.params["extended_bounds"] = params{"min": min, "max": max}
+	return a
+}
+
+// Aggregation adds sub-aggregation `v` as `name`.
+func (a *DateHistogramAggregation) Aggregation(name string, v Aggregation) *DateHistogramAggregation {
+	a.sub(name, v)
+	return a
+}
+
+// RangeAggregation buckets by ranges.
+type RangeAggregation struct{ agg }
+
+// Range returns a range aggregation of `field`.
+func Range(field string) *RangeAggregation {
+	return &RangeAggregation{newAgg("range", params{
+		"field":  field,
+		"ranges": []params{},
+	})}
+}
+
+// DateRange returns a date range aggregation of `field`.
+func DateRange(field string) *RangeAggregation {
+	return &RangeAggregation{newAgg("date_range", params{
+		"field":  field,
+		"ranges": []params{},
+	})}
+}
+
+// Range adds a range from `from` (inclusive) to `to` (exclusive), either of which may be nil.
+func (a *RangeAggregation) Range(from, to interface{}) *RangeAggregation {
+	return a.KeyedRange("", from, to)
+}
+
+// KeyedRange adds a range with `key`.
+func (a *RangeAggregation) KeyedRange(key string, from, to interface{}) *RangeAggregation {
+	r := params{}
+
+	if key != "" {
+		r["key"] = key
+	}
+
+	if from != nil {
+		r["from"] = from
+	}
+
+	if to != nil {
+		r["to"] = to
+	}
+
+	a.params["ranges"] = append(a.params["ranges"].([]params), r)
+	return a
+}
+
+// Format sets the date format.
+func (a *RangeAggregation) Format(v string) *RangeAggregation {
+	a.params["format"] = v
+	return a
+}
+
+// Aggregation adds sub-aggregation `v` as `name`.
+func (a *RangeAggregation) Aggregation(name string, v Aggregation) *RangeAggregation {
+	a.sub(name, v)
+	return a
+}
+
+// FiltersAggregation buckets by queries.
+type FiltersAggregation struct{ agg }
+
+// Filters returns a filters aggregation.
+func Filters() *FiltersAggregation {
+	return &FiltersAggregation{newAgg("filters", params{
+		"filters": map[string]query.Query{},
+	})}
+}
+
+// Filter adds a bucket of documents matching `q` as `name`.
+func (a *FiltersAggregation) Filter(name string, q query.Query) *FiltersAggregation {
+	a.params["filters"].(map[string]query.Query)[name] = q
+	return a
+}
+
+// OtherBucketKey enables a bucket of documents matching no filter as `key`.
+func (a *FiltersAggregation) OtherBucketKey(key string) *FiltersAggregation {
+	a.params["other_bucket_key"] = key
+	return a
+}
+
+// Aggregation adds sub-aggregation `v` as `name`.
+func (a *FiltersAggregation) Aggregation(name string, v Aggregation) *FiltersAggregation {
+	a.sub(name, v)
+	return a
+}
+
+// FilterAggregation is a single bucket of documents matching a query.
+type FilterAggregation struct {
+	query query.Query
+	aggs  map[string]Aggregation
+}
+
+// Filter returns a single bucket aggregation of documents matching `q`.
+func Filter(q query.Query) *FilterAggregation {
+	return &FilterAggregation{query: q}
+}
+
+// Aggregation adds sub-aggregation `v` as `name`.
+func (a *FilterAggregation) Aggregation(name string, v Aggregation) *FilterAggregation {
+	if a.aggs == nil {
+		a.aggs = make(map[string]Aggregation)
+	}
+	a.aggs[name] = v
+	return a
+}
+
+// MarshalJSON implementation.
+func (a *FilterAggregation) MarshalJSON() ([]byte, error) {
+	p := params{"filter": a.query}
+
+	if len(a.aggs) > 0 {
+		p["aggs"] = a.aggs
+	}
+
+	return json.Marshal(p)
+}
+
+// NestedAggregation aggregates nested objects.
+type NestedAggregation struct{ agg }
+
+// Nested returns a single bucket aggregation of the nested objects at `path`.
+func Nested(path string) *NestedAggregation {
+	return &NestedAggregation{newAgg("nested", params{"path": path})}
+}
+
+// Aggregation adds sub-aggregation `v` as `name`.
+func (a *NestedAggregation) Aggregation(name string, v Aggregation) *NestedAggregation {
+	a.sub(name, v)
+	return a
+}
+
+// CompositeAggregation pages through buckets of several sources.
+type CompositeAggregation struct{ agg }
+
+// Composite returns a composite aggregation.
+func Composite() *CompositeAggregation {
+	return &CompositeAggregation{newAgg("composite", params{
+		"sources": []params{},
+	})}
+}
+
+// Source adds a values source `v` as `name`, typically a terms,
+// histogram or date histogram aggregation.
+func (a *CompositeAggregation) Source(name string, v Aggregation) *CompositeAggregation {
+	a.params["sources"] = append(a.params["sources"].([]params), params{name: v})
+	return a
+}
+
+// Size sets the number of buckets per page.
+func (a *CompositeAggregation) Size(v int) *CompositeAggregation {
+	a.params["size"] = v
+	return a
+}
+
+// After sets the key of the bucket to page after, from the previous response's "after_key".
+func (a *CompositeAggregation) After(v map[string]interface{}) *CompositeAggregation {
+	a.params["after"] = v
+	return a
+}
+
+// Aggregation adds sub-aggregation `v` as `name`.
+func (a *CompositeAggregation) Aggregation(name string, v Aggregation) *CompositeAggregation {
+	a.sub(name, v)
+	return a
+}
+
+// MetricAggregation is a single or multi-value metric.
+type MetricAggregation struct{ agg }
+
+// Avg returns an average of `field`.
+func Avg(field string) *MetricAggregation {
+	return &MetricAggregation{newAgg("avg", params{"field": field})}
+}
+
+// Sum returns a sum of `field`.
+func Sum(field string) *MetricAggregation {
+	return &MetricAggregation{newAgg("sum", params{"field": field})}
+}
+
+// Min returns the minimum of `field`.
+func Min(field string) *MetricAggregation {
+	return &MetricAggregation{newAgg("min", params{"field": field})}
+}
+
+// Max returns the maximum of `field`.
+func Max(field string) *MetricAggregation {
+	return &MetricAggregation{newAgg("max", params{"field": field})}
+}
+
+// Stats returns the count, min, max, avg and sum of `field`.
+func Stats(field string) *MetricAggregation {
+	return &MetricAggregation{newAgg("stats", params{"field": field})}
+}
+
+// Cardinality returns the approximate distinct count of `field`.
+func Cardinality(field string) *MetricAggregation {
+	return &MetricAggregation{newAgg("cardinality", params{"field": field})}
+}
+
+// Percentiles returns the `percents` of `field`, defaulting to 1, 5, 25, 50, 75, 95 and 99.
+func Percentiles(field string, percents ...float64) *MetricAggregation {
+	p := params{"field": field}
+
+	if len(percents) > 0 {
+		p["percents"] = percents
+	}
+
+	return &MetricAggregation{newAgg("percentiles", p)}
+}
+
+// Missing sets the value of documents without a value.
+func (a *MetricAggregation) Missing(v interface{}) *MetricAggregation {
+	a.params["missing"] = v
+	return a
+}
+
+// PrecisionThreshold sets the cardinality precision threshold.
+func (a *MetricAggregation) PrecisionThreshold(v int) *MetricAggregation {
+	a.params["precision_threshold"] = v
+	return a
+}
+
+// TopHitsAggregation returns the top matching hits per bucket.
+type TopHitsAggregation struct{ agg }
+
+// TopHits returns a top hits aggregation.
+func TopHits() *TopHitsAggregation {
+	return &TopHitsAggregation{newAgg("top_hits", params{})}
+}
+
+// Size sets the number of hits.
+func (a *TopHitsAggregation) Size(v int) *TopHitsAggregation {
+	a.params["size"] = v
+	return a
+}
+
+// Sort adds a sort on `field` with `order`, "asc" or "desc".
+func (a *TopHitsAggregation) Sort(field, order string) *TopHitsAggregation {
+	sort, _ := a.params["sort"].([]params)
+	a.params["sort"] = append(sort, params{field: params{"order": order}})
+	return a
+}
+
+// Source sets the source fields returned in hits.
+func (a *TopHitsAggregation) Source(fields ...string) *TopHitsAggregation {
+	a.params["_source"] = fields
+	return a
+}
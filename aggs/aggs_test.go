@@ -0,0 +1,77 @@
+package aggs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tj/go-elastic/query"
+)
+
+func assertJSON(t *testing.T, expected string, v interface{}) {
+	b, err := json.Marshal(v)
+	assert.NoError(t, err, "marshaling")
+	assert.JSONEq(t, expected, string(b))
+}
+
+func TestTerms(t *testing.T) {
+	a := Terms("species").
+		Size(5).
+		Order("_count", "desc").
+		Aggregation("age", Avg("age"))
+
+	assertJSON(t, `{
+    "terms": { "field": "species", "size": 5, "order": { "_count": "desc" } },
+    "aggs": { "age": { "avg": { "field": "age" } } }
+  }`, a)
+}
+
+func TestDateHistogram(t *testing.T) {
+	a := DateHistogram("born").CalendarInterval("month").TimeZone("UTC").MinDocCount(0)
+	assertJSON(t, `{"date_histogram":{"field":"born","calendar_interval":"month","time_zone":"UTC","min_doc_count":0}}`, a)
+}
+
+func TestRange(t *testing.T) {
+	assertJSON(t, `{"range":{"field":"age","ranges":[{"to":2},{"from":2,"to":5},{"key":"old","from":5}]}}`, Range("age").Range(nil, 2).Range(2, 5).KeyedRange("old", 5, nil))
+	assertJSON(t, `{"date_range":{"field":"born","format":"yyyy","ranges":[{"from":"now-10y/y"}]}}`, DateRange("born").Format("yyyy").Range("now-10y/y", nil))
+}
+
+func TestFilters(t *testing.T) {
+	a := Filters().Filter("ferrets", query.Term("species", "ferret"))
+	assertJSON(t, `{"filters":{"filters":{"ferrets":{"term":{"species":{"value":"ferret"}}}}}}`, a)
+
+	f := Filter(query.Exists("owner")).Aggregation("count", Cardinality("owner"))
+	assertJSON(t, `{"filter":{"exists":{"field":"owner"}},"aggs":{"count":{"cardinality":{"field":"owner"}}}}`, f)
+}
+
+func TestComposite(t *testing.T) {
+	a := Composite().
+		Source("species", Terms("species")).
+		Source("month", DateHistogram("born").CalendarInterval("month")).
+		Size(100).
+		After(map[string]interface{}{"species": "cat", "month": 0})
+
+	assertJSON(t, `{
+    "composite": {
+      "sources": [
+        { "species": { "terms": { "field": "species" } } },
+        { "month": { "date_histogram": { "field": "born", "calendar_interval": "month" } } }
+      ],
+      "size": 100,
+      "after": { "species": "cat", "month": 0 }
+    }
+  }`, a)
+}
+
+func TestMetrics(t *testing.T) {
+	assertJSON(t, `{"stats":{"field":"weight"}}`, Stats("weight"))
+	assertJSON(t, `{"percentiles":{"field":"latency","percents":[50,99]}}`, Percentiles("latency", 50, 99))
+	assertJSON(t, `{"top_hits":{"size":1,"sort":[{"born":{"order":"desc"}}],"_source":["name"]}}`, TopHits().Size(1).Sort("born", "desc").Source("name"))
+	assertJSON(t, `{"nested":{"path":"toys"},"aggs":{"kinds":{"terms":{"field":"toys.kind"}}}}`, Nested("toys").Aggregation("kinds", Terms("toys.kind")))
+}
+
+func TestSearchSource(t *testing.T) {
+	s := query.Search(nil).Size(0).Aggregation("species", Terms("species"))
+	assertJSON(t, `{"size":0,"aggs":{"species":{"terms":{"field":"species"}}}}`, s)
+}
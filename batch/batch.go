@@ -1,9 +1,11 @@
-// Package batch lets you buffer bulk documents for insert. None of the methods
-// provided are thread-safe, you must synchronize if desired.
+// Package batch lets you buffer bulk documents for insert. None of the Batch
+// methods provided are thread-safe, you must synchronize if desired, or use
+// a BulkProcessor which flushes concurrently in the background.
 package batch
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 )
@@ -13,14 +15,50 @@ type Elasticsearch interface {
 	Bulk(io.Reader) error
 }
 
+// ContextElasticsearch interface is optionally implemented
+// by an Elasticsearch to support cancellation.
+type ContextElasticsearch interface {
+	BulkContext(context.Context, io.Reader) error
+}
+
 // Index metadata.
 type Index struct {
 	Index   string `json:"_index"`
-	Type    string `json:"_type"`
-	Routing string `json:"_routing,omitempty"`
+	Type    string `json:"_type,omitempty"`
+	Routing string `json:"routing,omitempty"`
 	ID      string `json:"_id,omitempty"`
 }
 
+// BulkMeta is optionally implemented by documents to choose their own
+// index, ID and routing, for example a daily index such as "logs-17-03-05".
+// Empty values default to the batch Index, an auto-generated ID and no routing.
+type BulkMeta interface {
+	BulkMeta() (index, id, routing string)
+}
+
+// Typeless interface is optionally implemented by an Elasticsearch to
+// report that the cluster does not support mapping types.
+type Typeless interface {
+	Typeless(context.Context) (bool, error)
+}
+
+// typeName returns type `kind`, or an empty string when `es` is Typeless.
+// When detection fails, for example without the privilege to request the
+// cluster version, `kind` is used as configured.
+func typeName(ctx context.Context, es interface{}, kind string) string {
+	t, ok := es.(Typeless)
+	if !ok || kind == "" {
+		return kind
+	}
+
+	typeless, err := t.Typeless(ctx)
+	if err != nil || !typeless {
+		return kind
+	}
+
+	return ""
+}
+
 // IndexOp is an index operation.
 type IndexOp struct {
 	Index Index `json:"index"`
@@ -30,13 +68,16 @@ type IndexOp struct {
 // are flushed in a single write, however may allow streaming
 // in the future.
 type Batch struct {
-	Elastic Elasticsearch // Elasticsearch implementation
-	Docs    []interface{} // Docs buffered
-	Index   string        // Index name
-	Type    string        // Type name
+	Elastic    Elasticsearch // Elasticsearch implementation
+	Docs       []interface{} // Docs buffered
+	Index      string        // Index name
+	Type       string        // Type name, omitted when the Elasticsearch implementation is Typeless
+	Retry      *RetryPolicy  // Retry policy for failed items, requires a BulkResponder
+	DeadLetter DeadLetter    // Dead letter for documents which permanently failed, requires a BulkResponder
+	Stream     bool          // Stream the request and response rather than buffering, requires a BulkStreamer
 }
 
-// Add document.
+// Add document, or an Op such as Create, Update or Delete.
 func (b *Batch) Add(doc interface{}) {
 	b.Docs = append(b.Docs, doc)
 }
@@ -48,41 +89,141 @@ func (b *Batch) Size() int {
 
 // Bytes returns the request body.
 func (b *Batch) Bytes() (*bytes.Buffer, error) {
+	return b.bytes(b.Type)
+}
+
+// bytes returns the request body with type `kind`.
+func (b *Batch) bytes(kind string) (*bytes.Buffer, error) {
 	buf := new(bytes.Buffer)
 	enc := json.NewEncoder(buf)
 
+	for _, doc := range b.Docs {
+		if err := encode(enc, b.Index, kind, doc); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf, nil
+}
+
+// encode the bulk action and source of `doc` to `enc`, where `doc`
+// is either an Op or a document to be indexed.
+func encode(enc *json.Encoder, index, kind string, doc interface{}) error {
 	op := IndexOp{
 		Index: Index{
-			Index: b.Index,
-			Type:  b.Type,
+			Index: index,
+			Type:  kind,
 		},
 	}
 
-	for _, doc := range b.Docs {
-		if err := enc.Encode(op); err != nil {
-			return nil, err
+	if m, ok := doc.(BulkMeta); ok {
+		i, id, routing := m.BulkMeta()
+		if i != "" {
+			op.Index.Index = i
 		}
+		op.Index.ID = id
+		op.Index.Routing = routing
+	}
 
-		if err := enc.Encode(doc); err != nil {
-			return nil, err
-		}
+	var action, source interface{} = op, doc
+
+	if op, ok := doc.(Op); ok {
+		action, source = op.bulk(index, kind)
 	}
 
-	return buf, nil
+	if err := enc.Encode(action); err != nil {
+		return err
+	}
+
+	if source == nil {
+		return nil
+	}
+
+	return enc.Encode(source)
 }
 
 // Flush checks in bulk.
-func (b *Batch) Flush() (err error) {
+func (b *Batch) Flush() error {
+	return b.FlushContext(context.Background())
+}
+
+// FlushContext checks in bulk. The context is passed to the Elasticsearch
+// implementation when it supports ContextElasticsearch. When it is a
+// BulkResponder failed items are retried according to the Retry policy,
+// and a *FlushError is returned for those which permanently failed. When
+// Stream is set and it is a BulkStreamer, documents are encoded as the
+// request is sent, and the response decoded item-by-item.
+func (b *Batch) FlushContext(ctx context.Context) error {
 	if b.Size() == 0 {
 		return nil
 	}
 
-	buf, err := b.Bytes()
+	kind := typeName(ctx, b.Elastic, b.Type)
+
+	if e, ok := b.Elastic.(BulkStreamer); ok && b.Stream {
+		return b.stream(ctx, e, kind)
+	}
+
+	if e, ok := b.Elastic.(BulkResponder); ok {
+		return b.send(ctx, e, kind)
+	}
+
+	buf, err := b.bytes(kind)
 	if err != nil {
 		return err
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	b.Docs = nil
 
+	if e, ok := b.Elastic.(ContextElasticsearch); ok {
+		return e.BulkContext(ctx, buf)
+	}
+
 	return b.Elastic.Bulk(buf)
 }
+
+// send the docs with retries.
+func (b *Batch) send(ctx context.Context, e BulkResponder, kind string) error {
+	var actions []*action
+
+	for _, doc := range b.Docs {
+		a, err := newAction(b.Index, kind, doc)
+		if err != nil {
+			return err
+		}
+		actions = append(actions, a)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.Docs = nil
+
+	s := &sender{
+		Elastic: e,
+		Retry:   b.Retry,
+	}
+
+	return b.failed(s.Send(ctx, actions))
+}
+
+// failed returns a *FlushError for `failures`, if any,
+// writing them to the dead letter.
+func (b *Batch) failed(failures []Failure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	err := &FlushError{Failures: failures}
+
+	if b.DeadLetter != nil {
+		err.DeadLetterErrors = writeDeadLetters(context.Background(), b.DeadLetter, failures)
+	}
+
+	return err
+}
@@ -1,8 +1,12 @@
 package batch
 
 import (
+	"context"
+	"errors"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/logfmt"
@@ -79,3 +83,82 @@ func TestClient_Bulk(t *testing.T) {
 	assert.Equal(t, "cat", out.Aggregations.Species.Buckets[1].Key)
 	assert.Equal(t, 1, out.Aggregations.Species.Buckets[1].DocDount)
 }
+
+// event with its own index, ID and routing.
+type event struct {
+	ID   string    `json:"id"`
+	User string    `json:"user"`
+	Time time.Time `json:"time"`
+}
+
+// BulkMeta implementation.
+func (e event) BulkMeta() (string, string, string) {
+	return "logs-" + e.Time.Format("06-01-02"), e.ID, e.User
+}
+
+func TestBatch_BulkMeta(t *testing.T) {
+	b := &Batch{
+		Index: "logs",
+	}
+
+	b.Add(event{"1", "tobi", time.Date(2017, 3, 5, 0, 0, 0, 0, time.UTC)})
+	b.Add(event{"", "", time.Time{}})
+
+	buf, err := b.Bytes()
+	assert.NoError(t, err)
+
+	expected := `{"index":{"_index":"logs-17-03-05","routing":"tobi","_id":"1"}}
+{"id":"1","user":"tobi","time":"2017-03-05T00:00:00Z"}
+{"index":{"_index":"logs-01-01-01"}}
+{"id":"","user":"","time":"0001-01-01T00:00:00Z"}
+`
+
+	assert.Equal(t, expected, buf.String())
+}
+
+// typeless Elasticsearch.
+type typeless struct {
+	responder
+	err   error
+	calls int32
+}
+
+// Typeless implementation.
+func (es *typeless) Typeless(context.Context) (bool, error) {
+	atomic.AddInt32(&es.calls, 1)
+	return es.err == nil, es.err
+}
+
+func TestBatch_Typeless(t *testing.T) {
+	es := &typeless{}
+
+	b := &Batch{
+		Elastic: es,
+		Index:   "animals",
+		Type:    "pet",
+	}
+
+	b.Add(pet{"Tobi", "ferret"})
+	assert.NoError(t, b.Flush())
+
+	assert.Equal(t, [][]string{
+		{`{"index":{"_index":"animals"}}`, `{"name":"Tobi","species":"ferret"}`},
+	}, es.requests)
+}
+
+func TestBatch_Typeless_error(t *testing.T) {
+	es := &typeless{err: errors.New("elastic: 403 forbidden")}
+
+	b := &Batch{
+		Elastic: es,
+		Index:   "animals",
+		Type:    "pet",
+	}
+
+	b.Add(pet{"Tobi", "ferret"})
+	assert.NoError(t, b.Flush())
+
+	assert.Equal(t, [][]string{
+		{`{"index":{"_index":"animals","_type":"pet"}}`, `{"name":"Tobi","species":"ferret"}`},
+	}, es.requests)
+}
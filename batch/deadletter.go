@@ -0,0 +1,225 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DeadLetter receives documents which permanently failed to index.
+type DeadLetter interface {
+	Write(context.Context, *DeadLetterEntry) error
+}
+
+// DeadLetterEntry is a document which permanently failed to index.
+type DeadLetterEntry struct {
+	Action    string    `json:"action"`           // Bulk action line
+	Source    string    `json:"source"`           // Document source line
+	Status    int       `json:"status,omitempty"` // Status code, if any
+	Reason    string    `json:"reason"`           // Failure reason
+	Timestamp time.Time `json:"timestamp"`        // Time of failure
+}
+
+// newDeadLetterEntry returns an entry for failure `f`.
+func newDeadLetterEntry(f Failure, now time.Time) *DeadLetterEntry {
+	lines := strings.SplitN(strings.TrimSuffix(string(f.Action), "\n"), "\n", 2)
+
+	e := &DeadLetterEntry{
+		Action:    lines[0],
+		Status:    f.Status,
+		Timestamp: now,
+	}
+
+	if len(lines) > 1 {
+		e.Source = lines[1]
+	}
+
+	if f.Err != nil {
+		e.Reason = f.Err.Error()
+	}
+
+	return e
+}
+
+// BatchDeadLetter is optionally implemented by a DeadLetter to write
+// entries in batches. A *FlushError reports the entries which failed.
+type BatchDeadLetter interface {
+	WriteBatch(context.Context, []*DeadLetterEntry) error
+}
+
+// writeDeadLetters writes `failures` to `dl`, returning the number of write errors.
+func writeDeadLetters(ctx context.Context, dl DeadLetter, failures []Failure) (errors int) {
+	now := time.Now()
+
+	var entries []*DeadLetterEntry
+	for _, f := range failures {
+		entries = append(entries, newDeadLetterEntry(f, now))
+	}
+
+	if b, ok := dl.(BatchDeadLetter); ok {
+		err := b.WriteBatch(ctx, entries)
+
+		if e, ok := err.(*FlushError); ok {
+			return len(e.Failures)
+		}
+
+		if err != nil {
+			return len(entries)
+		}
+
+		return 0
+	}
+
+	for _, e := range entries {
+		if err := dl.Write(ctx, e); err != nil {
+			errors++
+		}
+	}
+
+	return
+}
+
+// WriterDeadLetter writes entries as newline-delimited JSON.
+type WriterDeadLetter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterDeadLetter returns a dead letter writing to `w`.
+func NewWriterDeadLetter(w io.Writer) *WriterDeadLetter {
+	return &WriterDeadLetter{w: w}
+}
+
+// NewFileDeadLetter returns a dead letter appending to the file at `path`,
+// which should be closed when done.
+func NewFileDeadLetter(path string) (*WriterDeadLetter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWriterDeadLetter(f), nil
+}
+
+// Write implementation.
+func (d *WriterDeadLetter) Write(ctx context.Context, e *DeadLetterEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err = d.w.Write(append(b, '\n'))
+	return err
+}
+
+// Close closes the underlying writer when it is an io.Closer.
+func (d *WriterDeadLetter) Close() error {
+	if c, ok := d.w.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
+
+// IndexDeadLetter indexes entries as documents in another index.
+type IndexDeadLetter struct {
+	Elastic Elasticsearch // Elasticsearch implementation
+	Index   string        // Index name
+	Type    string        // Type name
+}
+
+// Write implementation.
+func (d *IndexDeadLetter) Write(ctx context.Context, e *DeadLetterEntry) error {
+	return d.WriteBatch(ctx, []*DeadLetterEntry{e})
+}
+
+// WriteBatch implementation.
+func (d *IndexDeadLetter) WriteBatch(ctx context.Context, entries []*DeadLetterEntry) error {
+	b := &Batch{
+		Elastic: d.Elastic,
+		Index:   d.Index,
+		Type:    d.Type,
+	}
+
+	for _, e := range entries {
+		b.Add(e)
+	}
+
+	return b.FlushContext(ctx)
+}
+
+// Replay reads dead letter entries from `r` written by a WriterDeadLetter, and
+// indexes them in bulk requests of `size` entries, returning the number of
+// entries replayed. Entries which fail again are returned in a *FlushError,
+// and written to dead letter `dl` when non-nil.
+func Replay(ctx context.Context, r io.Reader, es BulkResponder, size int, dl DeadLetter) (n int, err error) {
+	var actions []*action
+	var failures []Failure
+
+	s := &sender{
+		Elastic: es,
+	}
+
+	flush := func() {
+		if len(actions) == 0 {
+			return
+		}
+
+		f := s.Send(ctx, actions)
+		n += len(actions) - len(f)
+		failures = append(failures, f...)
+		actions = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64<<20)
+
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		e := new(DeadLetterEntry)
+		if err := json.Unmarshal(scanner.Bytes(), e); err != nil {
+			return n, err
+		}
+
+		body := e.Action + "\n"
+		if e.Source != "" {
+			body += e.Source + "\n"
+		}
+
+		actions = append(actions, &action{doc: e, body: []byte(body)})
+
+		if len(actions) >= size {
+			flush()
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return n, err
+	}
+
+	flush()
+
+	if len(failures) == 0 {
+		return n, nil
+	}
+
+	e := &FlushError{Failures: failures}
+
+	if dl != nil {
+		e.DeadLetterErrors = writeDeadLetters(ctx, dl, failures)
+	}
+
+	return n, e
+}
@@ -0,0 +1,145 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tj/go-elastic"
+)
+
+func TestWriterDeadLetter(t *testing.T) {
+	es := &responder{
+		respond: func(lines []string) *elastic.BulkResponse {
+			return &elastic.BulkResponse{
+				Errors: true,
+				Items: []*elastic.BulkResponseItem{
+					{Index: &elastic.BulkResponseItemResult{Status: 201}},
+					{Index: &elastic.BulkResponseItemResult{Status: 400, Error: &elastic.ErrorCause{Type: "mapper_parsing_exception", Reason: "failed to parse"}}},
+				},
+			}
+		},
+	}
+
+	var buf bytes.Buffer
+
+	b := &Batch{
+		Elastic:    es,
+		Index:      "animals",
+		Type:       "pet",
+		DeadLetter: NewWriterDeadLetter(&buf),
+	}
+
+	b.Add(pet{"Tobi", "ferret"})
+	b.Add(pet{"Loki", "ferret"})
+	assert.Error(t, b.Flush())
+
+	var e DeadLetterEntry
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &e))
+	assert.Equal(t, `{"index":{"_index":"animals","_type":"pet"}}`, e.Action)
+	assert.Equal(t, `{"name":"Loki","species":"ferret"}`, e.Source)
+	assert.Equal(t, 400, e.Status)
+	assert.Equal(t, "mapper_parsing_exception: failed to parse", e.Reason)
+	assert.WithinDuration(t, time.Now(), e.Timestamp, time.Minute)
+}
+
+func TestIndexDeadLetter(t *testing.T) {
+	es := &responder{}
+
+	dl := &IndexDeadLetter{Elastic: es, Index: "dead-letters", Type: "entry"}
+	err := dl.Write(context.Background(), &DeadLetterEntry{Action: "{}", Source: "{}", Reason: "boom"})
+	assert.NoError(t, err)
+
+	assert.Len(t, es.requests, 1)
+	assert.Equal(t, `{"index":{"_index":"dead-letters","_type":"entry"}}`, es.requests[0][0])
+	assert.Contains(t, es.requests[0][1], `"reason":"boom"`)
+}
+
+// failingDeadLetter fails every write.
+type failingDeadLetter struct{}
+
+// Write implementation.
+func (failingDeadLetter) Write(context.Context, *DeadLetterEntry) error {
+	return errors.New("boom")
+}
+
+func TestBulkProcessor_DeadLetter(t *testing.T) {
+	es := &responder{
+		respond: func(lines []string) *elastic.BulkResponse {
+			return &elastic.BulkResponse{
+				Items: []*elastic.BulkResponseItem{
+					{Index: &elastic.BulkResponseItemResult{Status: 400, Error: &elastic.ErrorCause{Reason: "boom"}}},
+				},
+			}
+		},
+	}
+
+	p := &BulkProcessor{Elastic: es, Index: "animals", DeadLetter: failingDeadLetter{}}
+	p.Start()
+	assert.NoError(t, p.Add(pet{"Tobi", "ferret"}))
+	assert.NoError(t, p.Close(context.Background()))
+	assert.Equal(t, int64(1), p.Stats().DeadLetterErrors)
+}
+
+func TestIndexDeadLetter_batch(t *testing.T) {
+	es := &responder{}
+
+	dl := &IndexDeadLetter{Elastic: es, Index: "dead-letters"}
+	n := writeDeadLetters(context.Background(), dl, []Failure{
+		{Action: []byte("{}\n{}\n"), Err: errors.New("boom")},
+		{Action: []byte("{}\n{}\n"), Err: errors.New("boom")},
+	})
+
+	assert.Equal(t, 0, n)
+	assert.Len(t, es.requests, 1)
+	assert.Len(t, es.requests[0], 4)
+}
+
+func TestReplay(t *testing.T) {
+	entries := `{"action":"{\"index\":{\"_index\":\"animals\"}}","source":"{\"name\":\"Tobi\"}","reason":"boom"}
+{"action":"{\"index\":{\"_index\":\"animals\"}}","source":"{\"name\":\"Loki\"}","reason":"boom"}
+
+{"action":"{\"delete\":{\"_index\":\"animals\",\"_id\":\"1\"}}","reason":"boom"}
+`
+
+	// rejects Loki again
+	es := &responder{
+		respond: func(lines []string) *elastic.BulkResponse {
+			res := &elastic.BulkResponse{}
+			for _, line := range lines {
+				switch {
+				case strings.Contains(line, "Loki"):
+					res.Items[len(res.Items)-1].Index = &elastic.BulkResponseItemResult{Status: 400, Error: &elastic.ErrorCause{Type: "mapper_parsing_exception"}}
+				case strings.HasPrefix(line, `{"index"`):
+					res.Items = append(res.Items, &elastic.BulkResponseItem{Index: &elastic.BulkResponseItemResult{Status: 201}})
+				case strings.HasPrefix(line, `{"delete"`):
+					res.Items = append(res.Items, &elastic.BulkResponseItem{Delete: &elastic.BulkResponseItemResult{Status: 200}})
+				}
+			}
+			return res
+		},
+	}
+
+	var buf bytes.Buffer
+
+	n, err := Replay(context.Background(), strings.NewReader(entries), es, 2, NewWriterDeadLetter(&buf))
+	assert.Equal(t, 2, n)
+	assert.IsType(t, &FlushError{}, err)
+	assert.Len(t, err.(*FlushError).Failures, 1)
+
+	assert.Equal(t, [][]string{
+		{`{"index":{"_index":"animals"}}`, `{"name":"Tobi"}`, `{"index":{"_index":"animals"}}`, `{"name":"Loki"}`},
+		{`{"delete":{"_index":"animals","_id":"1"}}`},
+	}, es.requests)
+
+	var e DeadLetterEntry
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &e))
+	assert.Equal(t, `{"name":"Loki"}`, e.Source)
+	assert.Equal(t, 400, e.Status)
+}
@@ -0,0 +1,90 @@
+package batch
+
+import "github.com/tj/go-elastic"
+
+// Op is a bulk operation, which may be added to a Batch or
+// BulkProcessor in place of a document to be indexed.
+type Op interface {
+	bulk(index, kind string) (action, source interface{})
+}
+
+// Meta is the bulk action metadata of an operation.
+type Meta struct {
+	Index         string `json:"_index,omitempty"`          // Index name, defaults to the batch Index
+	Type          string `json:"_type,omitempty"`           // Type name, defaults to the batch Type
+	ID            string `json:"_id,omitempty"`             // Document ID
+	Routing       string `json:"routing,omitempty"`         // Routing value
+	Version       int64  `json:"version,omitempty"`         // Document version
+	VersionType   string `json:"version_type,omitempty"`    // Version type such as "external"
+	IfSeqNo       *int64 `json:"if_seq_no,omitempty"`       // Sequence number for optimistic concurrency control
+	IfPrimaryTerm *int64 `json:"if_primary_term,omitempty"` // Primary term for optimistic concurrency control
+	Pipeline      string `json:"pipeline,omitempty"`        // Ingest pipeline
+}
+
+// defaults returns the metadata with defaults applied.
+func (m Meta) defaults(index, kind string) Meta {
+	if m.Index == "" {
+		m.Index = index
+	}
+
+	if m.Type == "" {
+		m.Type = kind
+	}
+
+	return m
+}
+
+// Create operation, failing if the document exists.
+type Create struct {
+	Meta
+	Doc interface{} // Document source
+}
+
+// bulk implementation.
+func (o Create) bulk(index, kind string) (interface{}, interface{}) {
+	return map[string]Meta{"create": o.defaults(index, kind)}, o.Doc
+}
+
+// Delete operation.
+type Delete struct {
+	Meta
+}
+
+// bulk implementation.
+func (o Delete) bulk(index, kind string) (interface{}, interface{}) {
+	return map[string]Meta{"delete": o.defaults(index, kind)}, nil
+}
+
+// Script for scripted updates.
+type Script = elastic.Script
+
+// Update operation, either a partial document or a script.
+type Update struct {
+	Meta
+	RetryOnConflict int         // Number of retries on version conflicts
+	Doc             interface{} // Partial document
+	Upsert          interface{} // Document indexed when missing
+	DocAsUpsert     bool        // Index Doc when missing
+	Script          *Script     // Script performing the update
+	ScriptedUpsert  bool        // Run Script when missing
+}
+
+// bulk implementation.
+func (o Update) bulk(index, kind string) (interface{}, interface{}) {
+	action := map[string]interface{}{
+		"update": struct {
+			Meta
+			RetryOnConflict int `json:"retry_on_conflict,omitempty"`
+		}{o.defaults(index, kind), o.RetryOnConflict},
+	}
+
+	source := &elastic.DocUpdate{
+		Doc:            o.Doc,
+		Upsert:         o.Upsert,
+		DocAsUpsert:    o.DocAsUpsert,
+		Script:         o.Script,
+		ScriptedUpsert: o.ScriptedUpsert,
+	}
+
+	return action, source
+}
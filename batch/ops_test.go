@@ -0,0 +1,47 @@
+package batch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatch_ops(t *testing.T) {
+	seq, term := int64(5), int64(1)
+
+	b := &Batch{
+		Index: "animals",
+		Type:  "pet",
+	}
+
+	b.Add(pet{"Tobi", "ferret"})
+	b.Add(Create{Meta: Meta{ID: "loki", Pipeline: "pets"}, Doc: pet{"Loki", "ferret"}})
+	b.Add(&Update{
+		Meta:            Meta{Index: "pets", ID: "tobi", Routing: "ferrets"},
+		RetryOnConflict: 3,
+		Doc:             map[string]string{"species": "cat"},
+		DocAsUpsert:     true,
+	})
+	b.Add(Update{
+		Meta:   Meta{ID: "manny", IfSeqNo: &seq, IfPrimaryTerm: &term},
+		Script: &Script{Source: "ctx._source.age += params.n", Params: map[string]interface{}{"n": 1}},
+		Upsert: map[string]int{"age": 1},
+	})
+	b.Add(Delete{Meta: Meta{ID: "jane", Version: 2, VersionType: "external"}})
+
+	buf, err := b.Bytes()
+	assert.NoError(t, err)
+
+	expected := `{"index":{"_index":"animals","_type":"pet"}}
+{"name":"Tobi","species":"ferret"}
+{"create":{"_index":"animals","_type":"pet","_id":"loki","pipeline":"pets"}}
+{"name":"Loki","species":"ferret"}
+{"update":{"_index":"pets","_type":"pet","_id":"tobi","routing":"ferrets","retry_on_conflict":3}}
+{"doc":{"species":"cat"},"doc_as_upsert":true}
+{"update":{"_index":"animals","_type":"pet","_id":"manny","if_seq_no":5,"if_primary_term":1}}
+{"upsert":{"age":1},"script":{"source":"ctx._source.age += params.n","params":{"n":1}}}
+{"delete":{"_index":"animals","_type":"pet","_id":"jane","version":2,"version_type":"external"}}
+`
+
+	assert.Equal(t, expected, buf.String())
+}
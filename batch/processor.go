@@ -0,0 +1,302 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tj/go-elastic"
+)
+
+// Errors.
+var (
+	ErrQueueFull = errors.New("batch: queue full")
+	ErrClosed    = errors.New("batch: processor closed")
+)
+
+// BulkResponder interface.
+type BulkResponder interface {
+	BulkResponseContext(context.Context, io.Reader) (*elastic.BulkResponse, error)
+}
+
+// Flush describes a bulk request made by a BulkProcessor.
+type Flush struct {
+	ID      int64 // Flush ID, unique per processor
+	Worker  int   // Worker performing the flush
+	Actions int   // Number of actions
+	Bytes   int   // Size of the request body
+}
+
+// Stats of a BulkProcessor.
+type Stats struct {
+	Flushed   int64 // Number of bulk requests
+	Succeeded int64 // Number of actions which succeeded
+	Failed    int64 // Number of actions which permanently failed
+	Retried   int64 // Number of action retries
+	Dropped   int64 // Number of actions dropped due to a full queue
+
+	DeadLetterErrors int64 // Number of failures which could not be written to the dead letter
+}
+
+// BulkProcessor indexes docs in bulk from a number of worker goroutines, flushing
+// when a worker has buffered enough actions or bytes, or on an interval. Its
+// methods are thread-safe, and it must be started with Start.
+type BulkProcessor struct {
+	Elastic       BulkResponder                             // Elasticsearch implementation
+	Index         string                                    // Index name
+	Type          string                                    // Type name, omitted when the Elasticsearch implementation is Typeless, checked when flushing
+	Workers       int                                       // Number of workers, defaults to 1
+	BulkActions   int                                       // Flush after this many actions, defaults to 1000
+	BulkBytes     int                                       // Flush after this many bytes, defaults to 5MB
+	FlushInterval time.Duration                             // Flush on this interval, zero disables
+	QueueSize     int                                       // Queued actions, defaults to BulkActions
+	DropWhenFull  bool                                      // Drop actions rather than block when the queue is full
+	Retry         *RetryPolicy                              // Retry policy for failed items, nil disables retries
+	Before        func(Flush)                               // Called before each flush
+	After         func(Flush, *elastic.BulkResponse, error) // Called after each flush, including retries
+	OnFailure     func(Failure)                             // Called with each document which permanently failed
+	DeadLetter    DeadLetter                                // Dead letter for documents which permanently failed
+
+	queue  chan *action
+	mu     sync.RWMutex
+	adding sync.WaitGroup // Pending adds, the queue is closed after they return
+	wg     sync.WaitGroup
+	done   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	id     int64
+	stats  Stats
+}
+
+// Start the workers.
+func (p *BulkProcessor) Start() {
+	if p.Workers == 0 {
+		p.Workers = 1
+	}
+
+	if p.BulkActions == 0 {
+		p.BulkActions = 1000
+	}
+
+	if p.BulkBytes == 0 {
+		p.BulkBytes = 5 << 20
+	}
+
+	if p.QueueSize == 0 {
+		p.QueueSize = p.BulkActions
+	}
+
+	p.queue = make(chan *action, p.QueueSize)
+	p.done = make(chan struct{})
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+
+	for i := 0; i < p.Workers; i++ {
+		p.wg.Add(1)
+		go p.work(i)
+	}
+}
+
+// Add document, blocking when the queue is full unless DropWhenFull
+// is set, in which case ErrQueueFull is returned.
+func (p *BulkProcessor) Add(doc interface{}) error {
+	return p.AddContext(context.Background(), doc)
+}
+
+// AddContext adds document, blocking when the queue is full until `ctx` is
+// done unless DropWhenFull is set, in which case ErrQueueFull is returned.
+func (p *BulkProcessor) AddContext(ctx context.Context, doc interface{}) error {
+	a, err := newAction(p.Index, p.Type, doc)
+	if err != nil {
+		return err
+	}
+
+	p.mu.RLock()
+	select {
+	case <-p.done:
+		p.mu.RUnlock()
+		return ErrClosed
+	default:
+		p.adding.Add(1)
+	}
+	p.mu.RUnlock()
+	defer p.adding.Done()
+
+	if p.DropWhenFull {
+		select {
+		case p.queue <- a:
+			return nil
+		default:
+			atomic.AddInt64(&p.stats.Dropped, 1)
+			return ErrQueueFull
+		}
+	}
+
+	select {
+	case p.queue <- a:
+		return nil
+	case <-p.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns the processor's stats.
+func (p *BulkProcessor) Stats() Stats {
+	return Stats{
+		Flushed:   atomic.LoadInt64(&p.stats.Flushed),
+		Succeeded: atomic.LoadInt64(&p.stats.Succeeded),
+		Failed:    atomic.LoadInt64(&p.stats.Failed),
+		Retried:   atomic.LoadInt64(&p.stats.Retried),
+		Dropped:   atomic.LoadInt64(&p.stats.Dropped),
+
+		DeadLetterErrors: atomic.LoadInt64(&p.stats.DeadLetterErrors),
+	}
+}
+
+// Close stops accepting documents and waits for queued documents to be
+// flushed. When `ctx` is done in-flight requests are cancelled.
+func (p *BulkProcessor) Close(ctx context.Context) error {
+	p.mu.Lock()
+	select {
+	case <-p.done:
+		p.mu.Unlock()
+		return ErrClosed
+	default:
+		close(p.done)
+	}
+	p.mu.Unlock()
+
+	drained := make(chan struct{})
+
+	go func() {
+		p.adding.Wait()
+		close(p.queue)
+		p.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
+
+// work buffers and flushes actions until the queue is closed.
+func (p *BulkProcessor) work(worker int) {
+	defer p.wg.Done()
+
+	var actions []*action
+	var size int
+	var tick <-chan time.Time
+
+	if p.FlushInterval > 0 {
+		t := time.NewTicker(p.FlushInterval)
+		defer t.Stop()
+		tick = t.C
+	}
+
+	flush := func() {
+		if len(actions) > 0 {
+			p.flush(worker, actions)
+			actions = nil
+			size = 0
+		}
+	}
+
+	for {
+		select {
+		case a, ok := <-p.queue:
+			if !ok {
+				flush()
+				return
+			}
+
+			actions = append(actions, a)
+			size += len(a.body)
+
+			if len(actions) >= p.BulkActions || size >= p.BulkBytes {
+				flush()
+			}
+		case <-tick:
+			flush()
+		}
+	}
+}
+
+// flush `actions`, retrying failed items.
+func (p *BulkProcessor) flush(worker int, actions []*action) {
+	var f Flush
+
+	if kind := typeName(p.ctx, p.Elastic, p.Type); kind != p.Type {
+		actions = retype(p.Index, kind, actions)
+	}
+
+	s := &sender{
+		Elastic: p.Elastic,
+		Retry:   p.Retry,
+		Before: func(actions []*action, size int) {
+			f = Flush{
+				ID:      atomic.AddInt64(&p.id, 1),
+				Worker:  worker,
+				Actions: len(actions),
+				Bytes:   size,
+			}
+
+			if p.Before != nil {
+				p.Before(f)
+			}
+		},
+		After: func(actions []*action, size int, res *elastic.BulkResponse, err error) {
+			atomic.AddInt64(&p.stats.Flushed, 1)
+
+			if err == nil {
+				atomic.AddInt64(&p.stats.Succeeded, int64(len(res.Succeeded())))
+			}
+
+			if p.After != nil {
+				p.After(f, res, err)
+			}
+		},
+		Retried: func(actions []*action) {
+			atomic.AddInt64(&p.stats.Retried, int64(len(actions)))
+		},
+	}
+
+	failures := s.Send(p.ctx, actions)
+
+	atomic.AddInt64(&p.stats.Failed, int64(len(failures)))
+
+	if p.DeadLetter != nil && len(failures) > 0 {
+		n := writeDeadLetters(context.Background(), p.DeadLetter, failures)
+		atomic.AddInt64(&p.stats.DeadLetterErrors, int64(n))
+	}
+
+	if p.OnFailure != nil {
+		for _, f := range failures {
+			p.OnFailure(f)
+		}
+	}
+}
+
+// retype returns `actions` encoded with type `kind`. Actions are encoded with the
+// configured Type when added, so that adding documents does not detect the mode.
+func retype(index, kind string, actions []*action) []*action {
+	v := make([]*action, len(actions))
+
+	for i, a := range actions {
+		v[i] = a
+		if b, err := newAction(index, kind, a.doc); err == nil {
+			v[i] = b
+		}
+	}
+
+	return v
+}
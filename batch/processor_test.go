@@ -0,0 +1,319 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tj/go-elastic"
+)
+
+// responder is a fake Elasticsearch.
+type responder struct {
+	sync.Mutex
+	requests [][]string
+	respond  func(lines []string) *elastic.BulkResponse
+}
+
+// BulkResponseContext implementation.
+func (r *responder) BulkResponseContext(ctx context.Context, body io.Reader) (*elastic.BulkResponse, error) {
+	var lines []string
+	s := bufio.NewScanner(body)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+
+	r.Lock()
+	r.requests = append(r.requests, lines)
+	r.Unlock()
+
+	if r.respond != nil {
+		return r.respond(lines), nil
+	}
+
+	res := &elastic.BulkResponse{}
+	for i := 0; i < len(lines)/2; i++ {
+		res.Items = append(res.Items, &elastic.BulkResponseItem{
+			Index: &elastic.BulkResponseItemResult{Status: 201},
+		})
+	}
+
+	return res, nil
+}
+
+// Bulk implementation.
+func (r *responder) Bulk(body io.Reader) error {
+	_, err := r.BulkResponseContext(context.Background(), body)
+	return err
+}
+
+func TestBulkProcessor(t *testing.T) {
+	es := &responder{}
+	var after []Flush
+
+	p := &BulkProcessor{
+		Elastic:     es,
+		Index:       "animals",
+		Type:        "pet",
+		BulkActions: 2,
+		After: func(f Flush, res *elastic.BulkResponse, err error) {
+			assert.NoError(t, err)
+			after = append(after, f)
+		},
+	}
+
+	p.Start()
+
+	assert.NoError(t, p.Add(pet{"Tobi", "ferret"}))
+	assert.NoError(t, p.Add(pet{"Loki", "ferret"}))
+	assert.NoError(t, p.Add(pet{"Manny", "cat"}))
+	assert.NoError(t, p.Close(context.Background()))
+
+	assert.Equal(t, ErrClosed, p.Add(pet{"Luna", "cat"}))
+
+	assert.Equal(t, [][]string{
+		{
+			`{"index":{"_index":"animals","_type":"pet"}}`,
+			`{"name":"Tobi","species":"ferret"}`,
+			`{"index":{"_index":"animals","_type":"pet"}}`,
+			`{"name":"Loki","species":"ferret"}`,
+		},
+		{
+			`{"index":{"_index":"animals","_type":"pet"}}`,
+			`{"name":"Manny","species":"cat"}`,
+		},
+	}, es.requests)
+
+	assert.Len(t, after, 2)
+	assert.Equal(t, 2, after[0].Actions)
+	assert.Equal(t, Stats{Flushed: 2, Succeeded: 3}, p.Stats())
+}
+
+func TestBulkProcessor_Typeless(t *testing.T) {
+	es := &typeless{}
+
+	p := &BulkProcessor{Elastic: es, Index: "animals", Type: "pet", DropWhenFull: true}
+	p.Start()
+
+	assert.NoError(t, p.Add(pet{"Tobi", "ferret"}))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&es.calls), "detected when adding")
+
+	assert.NoError(t, p.Close(context.Background()))
+	assert.Equal(t, [][]string{
+		{`{"index":{"_index":"animals"}}`, `{"name":"Tobi","species":"ferret"}`},
+	}, es.requests)
+}
+
+func TestBulkProcessor_interval(t *testing.T) {
+	es := &responder{}
+
+	p := &BulkProcessor{
+		Elastic:       es,
+		Index:         "animals",
+		FlushInterval: 10 * time.Millisecond,
+	}
+
+	p.Start()
+	defer p.Close(context.Background())
+
+	assert.NoError(t, p.Add(pet{"Tobi", "ferret"}))
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Equal(t, int64(1), p.Stats().Flushed)
+}
+
+func TestBulkProcessor_drop(t *testing.T) {
+	block := make(chan struct{})
+	es := &responder{
+		respond: func(lines []string) *elastic.BulkResponse {
+			<-block
+			return &elastic.BulkResponse{}
+		},
+	}
+
+	p := &BulkProcessor{
+		Elastic:      es,
+		Index:        "animals",
+		BulkActions:  1,
+		QueueSize:    1,
+		DropWhenFull: true,
+	}
+
+	p.Start()
+
+	var dropped int
+	for i := 0; i < 5; i++ {
+		if p.Add(pet{"Tobi", "ferret"}) == ErrQueueFull {
+			dropped++
+		}
+	}
+
+	close(block)
+	assert.NoError(t, p.Close(context.Background()))
+	assert.True(t, dropped > 0, "dropped")
+	assert.Equal(t, int64(dropped), p.Stats().Dropped)
+}
+
+// hanging Elasticsearch, responding only when the context is done.
+type hanging struct{}
+
+// BulkResponseContext implementation.
+func (hanging) BulkResponseContext(ctx context.Context, body io.Reader) (*elastic.BulkResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestBulkProcessor_Close_blocked(t *testing.T) {
+	p := &BulkProcessor{
+		Elastic:     hanging{},
+		Index:       "animals",
+		BulkActions: 1,
+		QueueSize:   1,
+	}
+
+	p.Start()
+
+	// fill the queue while the worker hangs, then block adding
+	added := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			added <- p.Add(pet{"Tobi", "ferret"})
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.Equal(t, context.DeadlineExceeded, p.Close(ctx))
+	assert.True(t, time.Since(start) < time.Second, "closed within deadline")
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-added:
+		case <-time.After(time.Second):
+			t.Fatal("add still blocked")
+		}
+	}
+}
+
+func TestBulkProcessor_retry(t *testing.T) {
+	var calls int
+
+	es := &responder{
+		respond: func(lines []string) *elastic.BulkResponse {
+			calls++
+			res := &elastic.BulkResponse{Errors: true}
+
+			for i := 1; i < len(lines); i += 2 {
+				status := 201
+				var err *elastic.ErrorCause
+
+				switch {
+				case strings.Contains(lines[i], "Loki") && calls == 1:
+					status = 429
+					err = &elastic.ErrorCause{Type: "es_rejected_execution_exception"}
+				case strings.Contains(lines[i], "Manny"):
+					status = 400
+					err = &elastic.ErrorCause{Type: "mapper_parsing_exception"}
+				}
+
+				res.Items = append(res.Items, &elastic.BulkResponseItem{
+					Index: &elastic.BulkResponseItemResult{Status: status, Error: err},
+				})
+			}
+
+			return res
+		},
+	}
+
+	var failures []Failure
+
+	p := &BulkProcessor{
+		Elastic: es,
+		Index:   "animals",
+		Retry:   &RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond},
+		OnFailure: func(f Failure) {
+			failures = append(failures, f)
+		},
+	}
+
+	p.Start()
+	assert.NoError(t, p.Add(pet{"Tobi", "ferret"}))
+	assert.NoError(t, p.Add(pet{"Loki", "ferret"}))
+	assert.NoError(t, p.Add(pet{"Manny", "cat"}))
+	assert.NoError(t, p.Close(context.Background()))
+
+	assert.Len(t, es.requests, 2)
+	assert.Equal(t, []string{`{"index":{"_index":"animals"}}`, `{"name":"Loki","species":"ferret"}`}, es.requests[1])
+
+	assert.Len(t, failures, 1)
+	assert.Equal(t, pet{"Manny", "cat"}, failures[0].Doc)
+	assert.Equal(t, 400, failures[0].Status)
+	assert.Equal(t, Stats{Flushed: 2, Succeeded: 2, Failed: 1, Retried: 1}, p.Stats())
+}
+
+func TestBatch_FlushError(t *testing.T) {
+	es := &responder{
+		respond: func(lines []string) *elastic.BulkResponse {
+			return &elastic.BulkResponse{
+				Errors: true,
+				Items: []*elastic.BulkResponseItem{
+					{Index: &elastic.BulkResponseItemResult{Status: 400, Error: &elastic.ErrorCause{Type: "mapper_parsing_exception"}}},
+				},
+			}
+		},
+	}
+
+	b := &Batch{Elastic: es, Index: "animals"}
+	b.Add(pet{"Tobi", "ferret"})
+
+	err := b.Flush()
+	assert.Error(t, err)
+	assert.Len(t, err.(*FlushError).Failures, 1)
+	assert.Equal(t, 0, b.Size())
+}
+
+func TestBatch_FlushContext_canceled(t *testing.T) {
+	es := &responder{}
+
+	b := &Batch{Elastic: es, Index: "animals"}
+	b.Add(pet{"Tobi", "ferret"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := b.FlushContext(ctx)
+	assert.True(t, errors.Is(err, context.Canceled), "canceled")
+	assert.Equal(t, 1, b.Size())
+	assert.Len(t, es.requests, 0)
+}
+
+func TestFlushError(t *testing.T) {
+	err := &FlushError{Failures: []Failure{{Err: &elastic.Error{Status: 429}}}}
+	assert.True(t, elastic.IsTooManyRequests(err), "too many requests")
+	assert.True(t, errors.Is(&FlushError{Failures: []Failure{{Err: context.DeadlineExceeded}}}, context.DeadlineExceeded), "deadline")
+	assert.Equal(t, "batch: documents failed to index", (&FlushError{}).Error())
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := &RetryPolicy{MaxRetries: 3}
+
+	for i := 0; i < 10; i++ {
+		d := p.backoff(1)
+		assert.True(t, d >= 50*time.Millisecond && d <= 100*time.Millisecond, "default")
+	}
+
+	p.MaxBackoff = 150 * time.Millisecond
+	assert.True(t, p.backoff(10) <= 150*time.Millisecond, "max")
+}
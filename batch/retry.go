@@ -0,0 +1,302 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/tj/go-elastic"
+)
+
+// DefaultRetryStatuses are the item status codes retried by default.
+var DefaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusServiceUnavailable,
+}
+
+// RetryPolicy for failed bulk items. Only the items which failed
+// with a retryable status are retried.
+type RetryPolicy struct {
+	MaxRetries int           // Maximum number of retries per item
+	MinBackoff time.Duration // Backoff before the first retry, defaults to 100ms
+	MaxBackoff time.Duration // Maximum backoff between retries, defaults to 10s
+	Statuses   []int         // Retryable status codes, defaults to DefaultRetryStatuses
+	Conflicts  bool          // Retry version conflicts
+}
+
+// backoff returns the jittered backoff before retry `n`, starting at 1,
+// with the defaults of elastic.DefaultRetryPolicy.
+func (p *RetryPolicy) backoff(n int) time.Duration {
+	r := elastic.DefaultRetryPolicy()
+
+	if p.MinBackoff > 0 {
+		r.MinBackoff = p.MinBackoff
+	}
+
+	if p.MaxBackoff > 0 {
+		r.MaxBackoff = p.MaxBackoff
+	}
+
+	return r.Backoff(n)
+}
+
+// retryable returns true if `status` should be retried.
+func (p *RetryPolicy) retryable(status int) bool {
+	if status == http.StatusConflict {
+		return p.Conflicts
+	}
+
+	statuses := p.Statuses
+	if statuses == nil {
+		statuses = DefaultRetryStatuses
+	}
+
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Failure is a document which permanently failed to index.
+type Failure struct {
+	Doc    interface{} // Original document
+	Action []byte      // Bulk action and source lines
+	Status int         // Item or response status code, zero on network errors
+	Err    error       // Item error, or the request error
+}
+
+// FlushError is returned when documents fail to index.
+type FlushError struct {
+	Failures         []Failure // Documents which permanently failed
+	DeadLetterErrors int       // Number of failures which could not be written to the dead letter
+}
+
+// Error implementation.
+func (e *FlushError) Error() string {
+	if len(e.Failures) == 0 {
+		return "batch: documents failed to index"
+	}
+
+	return fmt.Sprintf("batch: %d documents failed to index, first: %s", len(e.Failures), e.Failures[0].Err)
+}
+
+// Unwrap returns the failure errors, so that errors.Is and errors.As
+// match request errors such as context.Canceled or an *elastic.Error.
+func (e *FlushError) Unwrap() []error {
+	var errs []error
+
+	for _, f := range e.Failures {
+		if f.Err != nil {
+			errs = append(errs, f.Err)
+		}
+	}
+
+	return errs
+}
+
+// action is a buffered bulk action.
+type action struct {
+	doc  interface{}
+	body []byte
+}
+
+// newAction returns an action for `doc`.
+func newAction(index, kind string, doc interface{}) (*action, error) {
+	var buf bytes.Buffer
+
+	if err := encode(json.NewEncoder(&buf), index, kind, doc); err != nil {
+		return nil, err
+	}
+
+	return &action{doc: doc, body: buf.Bytes()}, nil
+}
+
+// sender performs bulk requests with retries.
+type sender struct {
+	Elastic BulkResponder
+	Stream  BulkStreamer // Stream requests of actions without a body, encoding their docs with Index and Type
+	Index   string
+	Type    string
+	Retry   *RetryPolicy
+	Before  func(actions []*action, size int)
+	After   func(actions []*action, size int, res *elastic.BulkResponse, err error)
+	Retried func(actions []*action)
+}
+
+// Send `actions`, retrying failed items, and returning permanent failures.
+func (s *sender) Send(ctx context.Context, actions []*action) (failures []Failure) {
+	for n := 0; ; n++ {
+		t := &attempt{
+			sender:  s,
+			actions: actions,
+			retry:   s.Retry != nil && n < s.Retry.MaxRetries && ctx.Err() == nil,
+		}
+
+		t.done(s.send(ctx, t))
+		failures = append(failures, t.failures...)
+
+		if len(t.pending) == 0 {
+			return failures
+		}
+
+		if err := elastic.Sleep(ctx, s.Retry.backoff(n+1)); err != nil {
+			for _, a := range t.pending {
+				failures = append(failures, s.failure(a, 0, err))
+			}
+			return failures
+		}
+
+		if s.Retried != nil {
+			s.Retried(t.pending)
+		}
+
+		actions = t.pending
+	}
+}
+
+// send the actions of attempt `t`, recording each response item. The size
+// passed to Before and After is zero when streaming.
+func (s *sender) send(ctx context.Context, t *attempt) error {
+	var body io.Reader
+	var size int
+
+	if s.Stream != nil {
+		docs := make([]interface{}, len(t.actions))
+		for i, a := range t.actions {
+			docs[i] = a.doc
+		}
+		body = pipe(s.Index, s.Type, docs)
+	} else {
+		var buf bytes.Buffer
+		for _, a := range t.actions {
+			buf.Write(a.body)
+		}
+		body = &buf
+		size = buf.Len()
+	}
+
+	if s.Before != nil {
+		s.Before(t.actions, size)
+	}
+
+	var res *elastic.BulkResponse
+	var err error
+
+	if s.Stream != nil {
+		res, err = s.Stream.BulkStreamContext(ctx, body, t.item)
+	} else {
+		res, err = s.Elastic.BulkResponseContext(ctx, body)
+		if err == nil {
+			for _, item := range res.Items {
+				t.item(item)
+			}
+		}
+	}
+
+	if s.After != nil {
+		s.After(t.actions, size, res, err)
+	}
+
+	return err
+}
+
+// failure returns a failure for action `a`, encoding it when streamed.
+func (s *sender) failure(a *action, status int, err error) Failure {
+	f := Failure{Doc: a.doc, Action: a.body, Status: status, Err: err}
+
+	if f.Action == nil {
+		if a, err := newAction(s.Index, s.Type, a.doc); err == nil {
+			f.Action = a.body
+		}
+	}
+
+	return f
+}
+
+// attempt collects the outcome of a bulk request of actions.
+type attempt struct {
+	*sender
+	actions  []*action
+	retry    bool
+	items    int
+	pending  []*action
+	failures []Failure
+}
+
+// item records the response item of the next action.
+func (t *attempt) item(item *elastic.BulkResponseItem) error {
+	i := t.items
+	t.items++
+
+	if i >= len(t.actions) || !item.Failed() {
+		return nil
+	}
+
+	a := t.actions[i]
+	status, err := itemError(item)
+
+	if t.retry && t.Retry.retryable(status) {
+		t.pending = append(t.pending, a)
+		return nil
+	}
+
+	t.failures = append(t.failures, t.failure(a, status, err))
+	return nil
+}
+
+// done records request error `err`, if any, for the actions without a response
+// item. All actions fail when the response has an unexpected number of items.
+func (t *attempt) done(err error) {
+	if t.items > len(t.actions) || (err == nil && t.items < len(t.actions)) {
+		err := fmt.Errorf("batch: expected %d items in response, got %d", len(t.actions), t.items)
+		t.pending = nil
+		t.failures = nil
+		for _, a := range t.actions {
+			t.failures = append(t.failures, t.failure(a, 0, err))
+		}
+		return
+	}
+
+	if err == nil {
+		return
+	}
+
+	status := requestStatus(err)
+
+	for _, a := range t.actions[t.items:] {
+		if t.retry && status != 0 && t.Retry.retryable(status) {
+			t.pending = append(t.pending, a)
+			continue
+		}
+		t.failures = append(t.failures, t.failure(a, status, err))
+	}
+}
+
+// requestStatus returns the status code of request error `err`, or zero.
+func requestStatus(err error) int {
+	var e *elastic.Error
+	if errors.As(err, &e) {
+		return e.Status
+	}
+
+	return 0
+}
+
+// itemError returns the status code and error of failed `item`.
+func itemError(item *elastic.BulkResponseItem) (int, error) {
+	status := item.Status()
+
+	if r := item.Result(); r != nil && r.Error != nil {
+		return status, r.Error
+	}
+
+	return status, fmt.Errorf("batch: status %d", status)
+}
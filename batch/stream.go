@@ -0,0 +1,63 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/tj/go-elastic"
+)
+
+// BulkStreamer interface.
+type BulkStreamer interface {
+	BulkStreamContext(context.Context, io.Reader, func(*elastic.BulkResponseItem) error) (*elastic.BulkResponse, error)
+}
+
+// Reader returns the request body, encoding documents as it is read
+// rather than buffering them. It must be read to EOF or closed.
+func (b *Batch) Reader() *io.PipeReader {
+	return pipe(b.Index, b.Type, b.Docs)
+}
+
+// pipe returns a reader encoding `docs` as it is read.
+func pipe(index, kind string, docs []interface{}) *io.PipeReader {
+	r, w := io.Pipe()
+
+	go func() {
+		enc := json.NewEncoder(w)
+
+		for _, doc := range docs {
+			if err := encode(enc, index, kind, doc); err != nil {
+				w.CloseWithError(err)
+				return
+			}
+		}
+
+		w.Close()
+	}()
+
+	return r
+}
+
+// stream the docs, retrying failed items.
+func (b *Batch) stream(ctx context.Context, e BulkStreamer, kind string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	actions := make([]*action, len(b.Docs))
+	for i, doc := range b.Docs {
+		actions[i] = &action{doc: doc}
+	}
+
+	b.Docs = nil
+
+	s := &sender{
+		Stream: e,
+		Index:  b.Index,
+		Type:   kind,
+		Retry:  b.Retry,
+	}
+
+	return b.failed(s.Send(ctx, actions))
+}
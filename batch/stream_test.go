@@ -0,0 +1,106 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tj/go-elastic"
+)
+
+func TestBatch_Reader(t *testing.T) {
+	b := &Batch{Index: "animals", Type: "pet"}
+	b.Add(pet{"Tobi", "ferret"})
+	b.Add(Delete{Meta: Meta{ID: "loki"}})
+
+	expected, err := b.Bytes()
+	assert.NoError(t, err)
+
+	actual, err := ioutil.ReadAll(b.Reader())
+	assert.NoError(t, err)
+	assert.Equal(t, expected.String(), string(actual))
+}
+
+func TestBatch_Stream(t *testing.T) {
+	var requests int32
+
+	// responds to each action with a 201, or a 429
+	// for Loki on the first request and a 400 for Jane
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		res := &elastic.BulkResponse{}
+
+		s := bufio.NewScanner(r.Body)
+		for s.Scan() {
+			s.Scan()
+
+			var p pet
+			json.Unmarshal(s.Bytes(), &p)
+
+			item := &elastic.BulkResponseItemResult{Status: 201}
+
+			switch {
+			case p.Name == "Loki" && n == 1:
+				item = &elastic.BulkResponseItemResult{Status: 429, Error: &elastic.ErrorCause{Type: "es_rejected_execution_exception"}}
+			case p.Name == "Jane":
+				item = &elastic.BulkResponseItemResult{Status: 400, Error: &elastic.ErrorCause{Type: "mapper_parsing_exception"}}
+			}
+
+			res.Items = append(res.Items, &elastic.BulkResponseItem{Index: item})
+		}
+
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer ts.Close()
+
+	b := &Batch{
+		Elastic: elastic.New(ts.URL),
+		Index:   "animals",
+		Type:    "pet",
+		Retry:   &RetryPolicy{MaxRetries: 1},
+		Stream:  true,
+	}
+
+	b.Add(pet{"Tobi", "ferret"})
+	b.Add(pet{"Loki", "ferret"})
+	b.Add(pet{"Jane", "cat"})
+
+	err := b.FlushContext(context.Background())
+	assert.IsType(t, &FlushError{}, err)
+
+	failures := err.(*FlushError).Failures
+	assert.Len(t, failures, 1)
+	assert.Equal(t, pet{"Jane", "cat"}, failures[0].Doc)
+	assert.Equal(t, 400, failures[0].Status)
+	assert.Equal(t, `{"index":{"_index":"animals","_type":"pet"}}`+"\n"+`{"name":"Jane","species":"cat"}`+"\n", string(failures[0].Action))
+	assert.Equal(t, int32(2), requests)
+	assert.Equal(t, 0, b.Size())
+}
+
+func TestBatch_Stream_items(t *testing.T) {
+	// responds with an item too many
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.Write([]byte(`{ "items": [ { "index": { "status": 201 } }, { "index": { "status": 201 } } ] }`))
+	}))
+	defer ts.Close()
+
+	b := &Batch{
+		Elastic: elastic.New(ts.URL),
+		Index:   "animals",
+		Stream:  true,
+	}
+
+	b.Add(pet{"Tobi", "ferret"})
+
+	err := b.Flush()
+	assert.IsType(t, &FlushError{}, err)
+	assert.EqualError(t, err.(*FlushError).Failures[0].Err, "batch: expected 1 items in response, got 2")
+}
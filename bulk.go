@@ -0,0 +1,188 @@
+package elastic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrResponseTooLarge is returned when a response exceeds the client's MaxResponseSize.
+var ErrResponseTooLarge = errors.New("elastic: response too large")
+
+// streamer is implemented by response values which
+// are decoded as a stream rather than buffered.
+type streamer interface {
+	stream(io.Reader) error
+}
+
+// BulkResponse for _bulk.
+type BulkResponse struct {
+	Took   float64             `json:"took"`
+	Errors bool                `json:"errors"`
+	Items  []*BulkResponseItem `json:"items"`
+}
+
+// Failed returns the items which failed.
+func (r *BulkResponse) Failed() (v []*BulkResponseItem) {
+	for _, item := range r.Items {
+		if item.Failed() {
+			v = append(v, item)
+		}
+	}
+	return
+}
+
+// Succeeded returns the items which succeeded.
+func (r *BulkResponse) Succeeded() (v []*BulkResponseItem) {
+	for _, item := range r.Items {
+		if !item.Failed() {
+			v = append(v, item)
+		}
+	}
+	return
+}
+
+// ByStatus returns the items grouped by status code.
+func (r *BulkResponse) ByStatus() map[int][]*BulkResponseItem {
+	m := make(map[int][]*BulkResponseItem)
+	for _, item := range r.Items {
+		status := item.Status()
+		m[status] = append(m[status], item)
+	}
+	return m
+}
+
+// BulkResponseItem for _bulk.
+type BulkResponseItem struct {
+	Create *BulkResponseItemResult `json:"create,omitempty"`
+	Delete *BulkResponseItemResult `json:"delete,omitempty"`
+	Update *BulkResponseItemResult `json:"update,omitempty"`
+	Index  *BulkResponseItemResult `json:"index,omitempty"`
+}
+
+// Op returns the operation name such as "index" and its result.
+func (i *BulkResponseItem) Op() (string, *BulkResponseItemResult) {
+	switch {
+	case i.Index != nil:
+		return "index", i.Index
+	case i.Create != nil:
+		return "create", i.Create
+	case i.Update != nil:
+		return "update", i.Update
+	case i.Delete != nil:
+		return "delete", i.Delete
+	default:
+		return "", nil
+	}
+}
+
+// Result returns the operation's result.
+func (i *BulkResponseItem) Result() *BulkResponseItemResult {
+	_, r := i.Op()
+	return r
+}
+
+// Status returns the operation's status code.
+func (i *BulkResponseItem) Status() int {
+	if r := i.Result(); r != nil {
+		return r.Status
+	}
+	return 0
+}
+
+// Failed returns true if the operation failed. Note that deleting
+// a missing document responds with a 404 but is not a failure.
+func (i *BulkResponseItem) Failed() bool {
+	r := i.Result()
+	return r == nil || r.Error != nil
+}
+
+// BulkResponseItemResult for _bulk request responses.
+type BulkResponseItemResult struct {
+	Index       string      `json:"_index"`
+	Type        string      `json:"_type,omitempty"`
+	ID          string      `json:"_id"`
+	Version     int         `json:"_version"`
+	Result      string      `json:"result,omitempty"`
+	Shards      *Shards     `json:"_shards,omitempty"`
+	SeqNo       *int64      `json:"_seq_no,omitempty"`
+	PrimaryTerm *int64      `json:"_primary_term,omitempty"`
+	Status      int         `json:"status"`
+	Found       bool        `json:"found,omitempty"`
+	Error       *ErrorCause `json:"error,omitempty"`
+}
+
+// bulkStream decodes a _bulk response item-by-item.
+type bulkStream struct {
+	fn  func(*BulkResponseItem) error
+	res BulkResponse
+}
+
+// stream implementation.
+func (s *bulkStream) stream(r io.Reader) error {
+	dec := json.NewDecoder(r)
+
+	if err := expect(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t {
+		case "took":
+			err = dec.Decode(&s.res.Took)
+		case "errors":
+			err = dec.Decode(&s.res.Errors)
+		case "items":
+			err = s.items(dec)
+		default:
+			err = dec.Decode(new(json.RawMessage))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return expect(dec, '}')
+}
+
+// items decodes the items array.
+func (s *bulkStream) items(dec *json.Decoder) error {
+	if err := expect(dec, '['); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		item := new(BulkResponseItem)
+
+		if err := dec.Decode(item); err != nil {
+			return err
+		}
+
+		if err := s.fn(item); err != nil {
+			return err
+		}
+	}
+
+	return expect(dec, ']')
+}
+
+// expect reads delimiter `d` from `dec`.
+func expect(dec *json.Decoder, d json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if t != d {
+		return fmt.Errorf("elastic: expected %q in response, got %v", d, t)
+	}
+
+	return nil
+}
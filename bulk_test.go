@@ -0,0 +1,184 @@
+package elastic
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var bulkResponse = `{
+  "took": 30,
+  "errors": true,
+  "items": [
+    {
+      "index": {
+        "_index": "pets", "_id": "1", "_version": 1, "result": "created",
+        "_shards": { "total": 2, "successful": 1, "failed": 0 },
+        "_seq_no": 0, "_primary_term": 1, "status": 201
+      }
+    },
+    {
+      "delete": { "_index": "pets", "_id": "2", "_version": 1, "result": "not_found", "status": 404 }
+    },
+    {
+      "update": {
+        "_index": "pets", "_id": "3", "status": 404,
+        "error": {
+          "type": "document_missing_exception",
+          "reason": "[_doc][3]: document missing",
+          "index_uuid": "aAsFqTI0Tc2W0LCWgPNrOA",
+          "shard": "0",
+          "index": "pets"
+        }
+      }
+    },
+    {
+      "create": {
+        "_index": "pets", "_id": "4", "status": 400,
+        "error": {
+          "type": "mapper_parsing_exception",
+          "reason": "failed to parse field [age]",
+          "caused_by": { "type": "number_format_exception", "reason": "For input string: \"old\"" }
+        }
+      }
+    },
+    {
+      "index": { "_index": "pets", "_type": "pet", "_id": "5", "status": 429, "error": "EsRejectedExecutionException[rejected execution]" }
+    }
+  ]
+}`
+
+func TestBulkResponse(t *testing.T) {
+	var res BulkResponse
+	assert.NoError(t, json.Unmarshal([]byte(bulkResponse), &res))
+
+	item := res.Items[0]
+	op, r := item.Op()
+	assert.Equal(t, "index", op)
+	assert.Equal(t, "created", r.Result)
+	assert.Equal(t, int64(0), *r.SeqNo)
+	assert.Equal(t, int64(1), *r.PrimaryTerm)
+	assert.Equal(t, 2, r.Shards.Total)
+	assert.False(t, item.Failed())
+
+	r = res.Items[2].Result()
+	assert.Equal(t, "document_missing_exception", r.Error.Type)
+	assert.Equal(t, "0", r.Error.Shard)
+	assert.Equal(t, "aAsFqTI0Tc2W0LCWgPNrOA", r.Error.IndexUUID)
+
+	r = res.Items[3].Result()
+	assert.Equal(t, `mapper_parsing_exception: failed to parse field [age]: number_format_exception: For input string: "old"`, r.Error.Error())
+
+	r = res.Items[4].Result()
+	assert.Equal(t, "EsRejectedExecutionException[rejected execution]", r.Error.Reason)
+
+	assert.False(t, res.Items[1].Failed())
+	assert.Len(t, res.Succeeded(), 2)
+	assert.Len(t, res.Failed(), 3)
+
+	byStatus := res.ByStatus()
+	assert.Len(t, byStatus[404], 2)
+	assert.Len(t, byStatus[429], 1)
+}
+
+func TestClient_BulkStream(t *testing.T) {
+	var body string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(bulkResponse))
+	}))
+	defer ts.Close()
+
+	r, w := io.Pipe()
+	go func() {
+		w.Write([]byte(`{"delete":{"_index":"pets","_id":"2"}}` + "\n"))
+		w.Close()
+	}()
+
+	c := New(ts.URL)
+	c.Retry = DefaultRetryPolicy()
+	c.MaxResponseSize = 100
+
+	var items []*BulkResponseItem
+	res, err := c.BulkStream(r, func(item *BulkResponseItem) error {
+		items = append(items, item)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"delete":{"_index":"pets","_id":"2"}}`+"\n", body)
+	assert.Equal(t, float64(30), res.Took)
+	assert.True(t, res.Errors)
+	assert.Empty(t, res.Items)
+	assert.Len(t, items, 5)
+	assert.Equal(t, "created", items[0].Index.Result)
+	assert.Equal(t, "EsRejectedExecutionException[rejected execution]", items[4].Index.Error.Reason)
+}
+
+func TestClient_BulkStream_requestError(t *testing.T) {
+	r, w := io.Pipe()
+	written := make(chan error)
+
+	go func() {
+		_, err := w.Write([]byte(docs))
+		written <- err
+	}()
+
+	_, err := New("http://%zz").BulkStream(r, func(*BulkResponseItem) error { return nil })
+	assert.Error(t, err)
+
+	select {
+	case err := <-written:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("writer blocked")
+	}
+}
+
+func TestClient_MaxResponseSize(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(bulkResponse))
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL)
+	c.MaxResponseSize = int64(len(bulkResponse))
+	_, err := c.BulkResponse(nil)
+	assert.NoError(t, err)
+
+	c.MaxResponseSize--
+	_, err = c.BulkResponse(nil)
+	assert.Equal(t, ErrResponseTooLarge, err)
+}
+
+func TestClient_MaxResponseSize_retry(t *testing.T) {
+	var requests int
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(bulkResponse))
+	})
+
+	tsA := httptest.NewServer(handler)
+	defer tsA.Close()
+
+	tsB := httptest.NewServer(handler)
+	defer tsB.Close()
+
+	c := New(tsA.URL, tsB.URL)
+	c.Retry = &RetryPolicy{MaxAttempts: 3}
+	c.MaxResponseSize = 10
+
+	_, err := c.BulkResponse(strings.NewReader(`{"delete":{"_index":"pets","_id":"2"}}` + "\n"))
+	assert.Equal(t, ErrResponseTooLarge, err)
+	assert.Equal(t, 1, requests, "not retried")
+}
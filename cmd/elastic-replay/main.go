@@ -0,0 +1,63 @@
+// Command elastic-replay replays batch dead letter files, writing
+// documents which fail again to another dead letter file.
+//
+//	$ elastic-replay -url http://localhost:9200 -failed failed.json dead-letters.json
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/tj/go-elastic"
+	"github.com/tj/go-elastic/batch"
+)
+
+func main() {
+	url := flag.String("url", "http://localhost:9200", "Elasticsearch url")
+	size := flag.Int("size", 500, "Documents per bulk request")
+	failed := flag.String("failed", "failed.json", "Dead letter file for documents which fail again")
+	flag.Parse()
+
+	ctx := context.Background()
+	client := elastic.New(*url)
+
+	dl, err := batch.NewFileDeadLetter(*failed)
+	if err != nil {
+		log.Fatalf("error opening dead letter: %s", err)
+	}
+	defer dl.Close()
+
+	if flag.NArg() == 0 {
+		replay(ctx, client, dl, "stdin", os.Stdin, *size)
+		return
+	}
+
+	for _, path := range flag.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("error opening: %s", err)
+		}
+
+		replay(ctx, client, dl, path, f, *size)
+		f.Close()
+	}
+}
+
+// replay entries from `r`.
+func replay(ctx context.Context, client *elastic.Client, dl batch.DeadLetter, name string, r io.Reader, size int) {
+	n, err := batch.Replay(ctx, r, client, size, dl)
+
+	if e, ok := err.(*batch.FlushError); ok {
+		log.Printf("replayed %d documents from %s, %d failed: %s", n, name, len(e.Failures), e)
+		return
+	}
+
+	if err != nil {
+		log.Fatalf("error replaying %s after %d documents: %s", name, n, err)
+	}
+
+	log.Printf("replayed %d documents from %s", n, name)
+}
@@ -0,0 +1,95 @@
+package elastic
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// detectBackoff is the delay before retrying a failed mode detection.
+var detectBackoff = time.Minute
+
+// Mode is an API compatibility mode.
+type Mode int
+
+// Modes available.
+const (
+	ModeDetect     Mode = iota // Detect from the cluster version with GET /
+	ModeTyped                  // Mapping types, Elasticsearch 6 and earlier
+	ModeTypeless               // No mapping types, Elasticsearch 7+ and OpenSearch
+	ModeCompatible             // No mapping types, sending compatible-with=7 media types for Elasticsearch 8
+)
+
+// Typeless returns true if the cluster does not support mapping types, in which
+// case `_type` should be omitted. Unless the client's Mode is set the cluster
+// version is detected, see Client.Version. Failed detections are cached
+// for a short backoff, to avoid requesting the version on every call.
+func (c *Client) Typeless(ctx context.Context) (bool, error) {
+	mode, err := c.mode(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return mode != ModeTyped, nil
+}
+
+// mode returns the client's mode, detecting it when necessary.
+func (c *Client) mode(ctx context.Context) (Mode, error) {
+	if c.Mode != ModeDetect {
+		return c.Mode, nil
+	}
+
+	c.mu.Lock()
+	err := c.detectErr
+	backoff := time.Now().Before(c.detectAt)
+	c.mu.Unlock()
+
+	if err != nil && backoff {
+		return ModeDetect, err
+	}
+
+	mode, err := c.detect(ctx)
+	if err != nil && ctx.Err() == nil {
+		c.mu.Lock()
+		c.detectErr = err
+		c.detectAt = time.Now().Add(detectBackoff)
+		c.mu.Unlock()
+	}
+
+	return mode, err
+}
+
+// detect returns the mode of the cluster version.
+func (c *Client) detect(ctx context.Context) (Mode, error) {
+	info, err := c.cachedInfo(ctx)
+	if err != nil {
+		return ModeDetect, err
+	}
+
+	if info.Version.Distribution == "opensearch" {
+		return ModeTypeless, nil
+	}
+
+	// missing or unknown versions are assumed to support mapping types
+	v, err := ParseVersion(info.Version.Number)
+	if err != nil {
+		return ModeTyped, nil
+	}
+
+	if v.Major >= 7 {
+		return ModeTypeless, nil
+	}
+
+	return ModeTyped, nil
+}
+
+// mediaType returns the compatible-with=7 media type for `path`.
+func mediaType(path string) string {
+	path = strings.SplitN(path, "?", 2)[0]
+
+	if strings.HasSuffix(path, "/_bulk") || strings.Contains(path, "/_msearch") {
+		return "application/vnd.elasticsearch+x-ndjson; compatible-with=7"
+	}
+
+	return "application/vnd.elasticsearch+json; compatible-with=7"
+}
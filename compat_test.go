@@ -0,0 +1,92 @@
+package elastic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Typeless(t *testing.T) {
+	cases := []struct {
+		info     string
+		typeless bool
+	}{
+		{`{ "version": { "number": "2.1.1" } }`, false},
+		{`{ "version": { "number": "6.8.23" } }`, false},
+		{`{ "version": { "number": "7.17.0" } }`, true},
+		{`{ "version": { "number": "8.11.1", "build_flavor": "default" } }`, true},
+		{`{ "version": { "number": "1.3.2", "distribution": "opensearch" } }`, true},
+		{`{}`, false},
+		{`{ "version": { "number": "unknown" } }`, false},
+	}
+
+	for _, c := range cases {
+		var requests int
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Write([]byte(c.info))
+		}))
+
+		client := New(ts.URL)
+
+		for i := 0; i < 2; i++ {
+			typeless, err := client.Typeless(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, c.typeless, typeless, c.info)
+		}
+
+		assert.Equal(t, 1, requests, "cached")
+		ts.Close()
+	}
+}
+
+func TestClient_Mode(t *testing.T) {
+	headers := make(map[string]http.Header)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers[r.URL.Path] = r.Header
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	client.Mode = ModeCompatible
+
+	typeless, err := client.Typeless(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, typeless)
+
+	assert.NoError(t, client.Bulk(strings.NewReader(`{"delete":{"_index":"pets","_id":"1"}}`+"\n")))
+	assert.NoError(t, client.SearchIndex("pets", nil, nil))
+
+	assert.Equal(t, "application/vnd.elasticsearch+x-ndjson; compatible-with=7", headers["/_bulk"].Get("Content-Type"))
+	assert.Equal(t, "application/vnd.elasticsearch+x-ndjson; compatible-with=7", headers["/_bulk"].Get("Accept"))
+	assert.Equal(t, "application/vnd.elasticsearch+json; compatible-with=7", headers["/pets/_search"].Get("Content-Type"))
+	assert.Equal(t, "application/vnd.elasticsearch+json; compatible-with=7", headers["/pets/_search"].Get("Accept"))
+	assert.Len(t, headers, 2)
+}
+
+func TestClient_Typeless_error(t *testing.T) {
+	var requests int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{ "error": { "type": "security_exception", "reason": "action [cluster:monitor/main] is unauthorized" } }`))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Typeless(context.Background())
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, 1, requests, "cached")
+}
@@ -0,0 +1,46 @@
+package elastic
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// gzipBytes returns `b` compressed.
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// gzipPipe returns a reader compressing `r` as it is read. When
+// the reader is closed early `r` is closed, if it is an io.Closer.
+func gzipPipe(r io.Reader) *io.PipeReader {
+	pr, pw := io.Pipe()
+
+	go func() {
+		w := gzip.NewWriter(pw)
+
+		_, err := io.Copy(w, r)
+		if err == nil {
+			err = w.Close()
+		}
+
+		if c, ok := r.(io.Closer); ok && err != nil {
+			c.Close()
+		}
+
+		pw.CloseWithError(err)
+	}()
+
+	return pr
+}
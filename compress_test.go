@@ -0,0 +1,77 @@
+package elastic
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Compress(t *testing.T) {
+	var encoding, body string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+
+		var rd io.Reader = r.Body
+		if encoding == "gzip" {
+			rd, _ = gzip.NewReader(r.Body)
+		}
+
+		b, _ := ioutil.ReadAll(rd)
+		body = string(b)
+
+		assert.Equal(t, "gzip", r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte(`{ "took": 5, "errors": false, "items": [] }`))
+		zw.Close()
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL)
+	c.Compress = true
+	c.CompressMinSize = 50
+
+	t.Run("above threshold", func(t *testing.T) {
+		doc := `{"index":{}}` + "\n" + `{"name":"` + strings.Repeat("Tobi", 20) + `"}` + "\n"
+		res, err := c.BulkResponse(strings.NewReader(doc))
+		assert.NoError(t, err)
+		assert.Equal(t, float64(5), res.Took)
+		assert.Equal(t, "gzip", encoding)
+		assert.Equal(t, doc, body)
+	})
+
+	t.Run("below threshold", func(t *testing.T) {
+		doc := `{"index":{}}` + "\n" + `{"name":"Tobi"}` + "\n"
+		res, err := c.BulkResponse(strings.NewReader(doc))
+		assert.NoError(t, err)
+		assert.Equal(t, float64(5), res.Took)
+		assert.Equal(t, "", encoding)
+		assert.Equal(t, doc, body)
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		doc := `{"index":{}}` + "\n" + `{"name":"Tobi"}` + "\n"
+
+		r, w := io.Pipe()
+		go func() {
+			w.Write([]byte(doc))
+			w.Close()
+		}()
+
+		res, err := c.BulkStream(r, func(*BulkResponseItem) error {
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(5), res.Took)
+		assert.Equal(t, "gzip", encoding)
+		assert.Equal(t, doc, body)
+	})
+}
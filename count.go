@@ -0,0 +1,43 @@
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Count returns the number of documents in `index` matching `query`, where
+// `query` is a query clause such as those in the query package, or nil
+// to count all documents. An empty `index` counts across all indices.
+func (c *Client) Count(ctx context.Context, index string, query interface{}) (int64, error) {
+	var body io.Reader
+
+	if query != nil {
+		b, err := json.Marshal(map[string]interface{}{
+			"query": query,
+		})
+
+		if err != nil {
+			return 0, err
+		}
+
+		body = bytes.NewReader(b)
+	}
+
+	var res struct {
+		Count int64 `json:"count"`
+	}
+
+	path := "/_count"
+	if index != "" {
+		path = fmt.Sprintf("/%s/_count", index)
+	}
+
+	if err := c.RequestContext(ctx, "POST", path, body, &res); err != nil {
+		return 0, err
+	}
+
+	return res.Count, nil
+}
@@ -0,0 +1,39 @@
+package elastic
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Count(t *testing.T) {
+	var uri, body string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uri = r.URL.RequestURI()
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{ "count": 42, "_shards": { "total": 1, "successful": 1, "skipped": 0, "failed": 0 } }`))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	n, err := client.Count(context.Background(), "pets", map[string]interface{}{"term": map[string]string{"species": "ferret"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), n)
+	assert.Equal(t, "/pets/_count", uri)
+	assert.Equal(t, `{"query":{"term":{"species":"ferret"}}}`, body)
+
+	_, err = client.Count(context.Background(), "pets", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", body)
+
+	_, err = client.Count(context.Background(), "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "/_count", uri)
+}
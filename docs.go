@@ -0,0 +1,240 @@
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DocOptions configures document requests. Options which
+// do not apply to a request are ignored.
+type DocOptions struct {
+	Type            string   // Mapping type for typed clusters, defaults to "_doc"
+	Routing         string   // Routing value
+	Refresh         string   // Refresh policy, "true", "false" or "wait_for"
+	Version         int64    // Expected version
+	VersionType     string   // Version type such as "external"
+	IfSeqNo         *int64   // Expected sequence number for optimistic concurrency control
+	IfPrimaryTerm   *int64   // Expected primary term for optimistic concurrency control
+	Source          []string // Source fields included by Get
+	SourceExcludes  []string // Source fields excluded by Get
+	OpType          string   // Operation type of Index, "create" fails if the document exists
+	Pipeline        string   // Ingest pipeline of Index
+	RetryOnConflict int      // Retries of Update on version conflicts
+}
+
+// params returns the options as query string parameters.
+func (o *DocOptions) params() url.Values {
+	v := url.Values{}
+
+	if o == nil {
+		return v
+	}
+
+	set := func(k, s string) {
+		if s != "" {
+			v.Set(k, s)
+		}
+	}
+
+	set("routing", o.Routing)
+	set("refresh", o.Refresh)
+	set("version_type", o.VersionType)
+	set("_source_includes", strings.Join(o.Source, ","))
+	set("_source_excludes", strings.Join(o.SourceExcludes, ","))
+	set("op_type", o.OpType)
+	set("pipeline", o.Pipeline)
+
+	if o.Version != 0 {
+		v.Set("version", strconv.FormatInt(o.Version, 10))
+	}
+
+	if o.IfSeqNo != nil {
+		v.Set("if_seq_no", strconv.FormatInt(*o.IfSeqNo, 10))
+	}
+
+	if o.IfPrimaryTerm != nil {
+		v.Set("if_primary_term", strconv.FormatInt(*o.IfPrimaryTerm, 10))
+	}
+
+	if o.RetryOnConflict != 0 {
+		v.Set("retry_on_conflict", strconv.Itoa(o.RetryOnConflict))
+	}
+
+	return v
+}
+
+// kind returns the mapping type.
+func (o *DocOptions) kind() string {
+	if o == nil || o.Type == "" {
+		return "_doc"
+	}
+
+	return o.Type
+}
+
+// Doc is a document returned by Get or MultiGet.
+type Doc struct {
+	Index       string          `json:"_index"`
+	Type        string          `json:"_type,omitempty"`
+	ID          string          `json:"_id"`
+	Version     int64           `json:"_version"`
+	SeqNo       int64           `json:"_seq_no"`
+	PrimaryTerm int64           `json:"_primary_term"`
+	Routing     string          `json:"_routing,omitempty"`
+	Found       bool            `json:"found"`
+	Source      json.RawMessage `json:"_source,omitempty"`
+	Error       *ErrorCause     `json:"error,omitempty"`
+}
+
+// Decode the document source into `v`.
+func (d *Doc) Decode(v interface{}) error {
+	return json.Unmarshal(d.Source, v)
+}
+
+// DocResult is the result of a document write.
+type DocResult struct {
+	Index       string  `json:"_index"`
+	Type        string  `json:"_type,omitempty"`
+	ID          string  `json:"_id"`
+	Version     int64   `json:"_version"`
+	Result      string  `json:"result"`
+	Shards      *Shards `json:"_shards,omitempty"`
+	SeqNo       int64   `json:"_seq_no"`
+	PrimaryTerm int64   `json:"_primary_term"`
+}
+
+// Script for scripted updates.
+type Script struct {
+	Source string                 `json:"source"`           // Script source
+	Lang   string                 `json:"lang,omitempty"`   // Script language, defaults to painless
+	Params map[string]interface{} `json:"params,omitempty"` // Script parameters
+}
+
+// DocUpdate is an update, either a partial document or a script.
+type DocUpdate struct {
+	Doc            interface{} `json:"doc,omitempty"`             // Partial document
+	Upsert         interface{} `json:"upsert,omitempty"`          // Document indexed when missing
+	DocAsUpsert    bool        `json:"doc_as_upsert,omitempty"`   // Index Doc when missing
+	Script         *Script     `json:"script,omitempty"`          // Script performing the update
+	ScriptedUpsert bool        `json:"scripted_upsert,omitempty"` // Run Script when missing
+}
+
+// Get returns the document `id` in `index`. IsNotFound
+// reports whether the document or index is missing.
+func (c *Client) Get(ctx context.Context, index, id string, opts *DocOptions) (*Doc, error) {
+	path := c.docPath(ctx, index, id, opts)
+
+	doc := new(Doc)
+	if err := c.RequestContext(ctx, "GET", withParams(path, opts.params()), nil, doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// Exists returns true if the document `id` exists in `index`.
+func (c *Client) Exists(ctx context.Context, index, id string, opts *DocOptions) (bool, error) {
+	path := c.docPath(ctx, index, id, opts)
+
+	err := c.RequestContext(ctx, "HEAD", withParams(path, opts.params()), nil, nil)
+
+	if IsNotFound(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Index indexes `doc` in `index` as `id`, or with an auto-generated
+// ID when empty. Set the OpType option to "create" to fail with
+// a conflict when the document exists.
+func (c *Client) Index(ctx context.Context, index, id string, doc interface{}, opts *DocOptions) (*DocResult, error) {
+	path := c.docPath(ctx, index, id, opts)
+
+	method := "PUT"
+	if id == "" {
+		method = "POST"
+	}
+
+	return c.write(ctx, method, withParams(path, opts.params()), doc)
+}
+
+// Update updates the document `id` in `index`.
+func (c *Client) Update(ctx context.Context, index, id string, update *DocUpdate, opts *DocOptions) (*DocResult, error) {
+	path := fmt.Sprintf("/%s/_update/%s", index, url.PathEscape(id))
+	if !c.docTypeless(ctx, opts) {
+		path = fmt.Sprintf("/%s/%s/%s/_update", index, opts.kind(), url.PathEscape(id))
+	}
+
+	return c.write(ctx, "POST", withParams(path, opts.params()), update)
+}
+
+// Delete deletes the document `id` in `index`. IsNotFound
+// reports whether the document or index is missing.
+func (c *Client) Delete(ctx context.Context, index, id string, opts *DocOptions) (*DocResult, error) {
+	path := c.docPath(ctx, index, id, opts)
+
+	res := new(DocResult)
+	if err := c.RequestContext(ctx, "DELETE", withParams(path, opts.params()), nil, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// write `v` to `path`.
+func (c *Client) write(ctx context.Context, method, path string, v interface{}) (*DocResult, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(DocResult)
+	if err := c.RequestContext(ctx, method, path, bytes.NewReader(b), res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// docTypeless returns true if document paths omit the type. When detection
+// fails, for example without the privilege to request the cluster version,
+// the type is used only when set in `opts`, as the batch package does.
+func (c *Client) docTypeless(ctx context.Context, opts *DocOptions) bool {
+	typeless, err := c.Typeless(ctx)
+	if err != nil {
+		return opts == nil || opts.Type == ""
+	}
+
+	return typeless
+}
+
+// docPath returns the path of document `id` in `index`.
+func (c *Client) docPath(ctx context.Context, index, id string, opts *DocOptions) string {
+	kind := "_doc"
+	if !c.docTypeless(ctx, opts) {
+		kind = opts.kind()
+	}
+
+	path := fmt.Sprintf("/%s/%s", index, kind)
+
+	if id != "" {
+		path += "/" + url.PathEscape(id)
+	}
+
+	return path
+}
+
+// withParams returns `path` with query string `params`.
+func withParams(path string, params url.Values) string {
+	if len(params) == 0 {
+		return path
+	}
+
+	return path + "?" + params.Encode()
+}
@@ -0,0 +1,187 @@
+package elastic
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// docServer responds with `version` to GET /, and otherwise
+// with `status` and `body`, recording the request.
+func docServer(version string, status int, body string) (*httptest.Server, *http.Request, *string) {
+	req := new(http.Request)
+	reqBody := new(string)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.Write([]byte(`{ "version": { "number": "` + version + `" } }`))
+			return
+		}
+
+		b, _ := ioutil.ReadAll(r.Body)
+		*reqBody = string(b)
+		*req = *r
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+
+	return ts, req, reqBody
+}
+
+func TestClient_Get(t *testing.T) {
+	ts, req, _ := docServer("7.17.0", 200, `{
+    "_index": "pets", "_id": "tobi", "_version": 2, "_seq_no": 5, "_primary_term": 1,
+    "found": true, "_source": { "name": "Tobi", "species": "ferret" }
+  }`)
+	defer ts.Close()
+
+	doc, err := New(ts.URL).Get(context.Background(), "pets", "tobi", &DocOptions{
+		Routing: "ferrets",
+		Source:  []string{"name", "species"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "GET", req.Method)
+	assert.Equal(t, "/pets/_doc/tobi?_source_includes=name%2Cspecies&routing=ferrets", req.URL.RequestURI())
+	assert.True(t, doc.Found)
+	assert.Equal(t, int64(2), doc.Version)
+	assert.Equal(t, int64(5), doc.SeqNo)
+
+	var p pet
+	assert.NoError(t, doc.Decode(&p))
+	assert.Equal(t, pet{"Tobi", "ferret"}, p)
+}
+
+func TestClient_Get_notFound(t *testing.T) {
+	ts, _, _ := docServer("7.17.0", 404, `{ "_index": "pets", "_id": "tobi", "found": false }`)
+	defer ts.Close()
+
+	_, err := New(ts.URL).Get(context.Background(), "pets", "tobi", nil)
+	assert.True(t, IsNotFound(err))
+}
+
+func TestClient_Exists(t *testing.T) {
+	ts, req, _ := docServer("6.8.23", 404, ``)
+	defer ts.Close()
+
+	ok, err := New(ts.URL).Exists(context.Background(), "pets", "tobi", &DocOptions{Type: "pet"})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "HEAD", req.Method)
+	assert.Equal(t, "/pets/pet/tobi", req.URL.RequestURI())
+}
+
+func TestClient_Index(t *testing.T) {
+	ts, req, body := docServer("8.11.1", 201, `{
+    "_index": "pets", "_id": "tobi", "_version": 1, "result": "created",
+    "_shards": { "total": 2, "successful": 1, "failed": 0 }, "_seq_no": 0, "_primary_term": 1
+  }`)
+	defer ts.Close()
+
+	seq, term := int64(4), int64(1)
+
+	res, err := New(ts.URL).Index(context.Background(), "pets", "tobi", pet{"Tobi", "ferret"}, &DocOptions{
+		OpType:        "create",
+		Refresh:       "wait_for",
+		IfSeqNo:       &seq,
+		IfPrimaryTerm: &term,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "PUT", req.Method)
+	assert.Equal(t, "/pets/_doc/tobi?if_primary_term=1&if_seq_no=4&op_type=create&refresh=wait_for", req.URL.RequestURI())
+	assert.Equal(t, `{"name":"Tobi","species":"ferret"}`, *body)
+	assert.Equal(t, "created", res.Result)
+	assert.Equal(t, int64(1), res.Version)
+	assert.Equal(t, 1, res.Shards.Successful)
+}
+
+func TestClient_Index_autoID(t *testing.T) {
+	ts, req, _ := docServer("7.17.0", 201, `{ "_index": "pets", "_id": "x1", "result": "created" }`)
+	defer ts.Close()
+
+	res, err := New(ts.URL).Index(context.Background(), "pets", "", pet{"Tobi", "ferret"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "POST", req.Method)
+	assert.Equal(t, "/pets/_doc", req.URL.RequestURI())
+	assert.Equal(t, "x1", res.ID)
+}
+
+func TestClient_Update(t *testing.T) {
+	t.Run("typeless", func(t *testing.T) {
+		ts, req, body := docServer("7.17.0", 200, `{ "_index": "pets", "_id": "tobi", "_version": 3, "result": "updated" }`)
+		defer ts.Close()
+
+		res, err := New(ts.URL).Update(context.Background(), "pets", "tobi", &DocUpdate{
+			Script: &Script{Source: "ctx._source.age += params.n", Params: map[string]interface{}{"n": 1}},
+			Upsert: map[string]int{"age": 1},
+		}, &DocOptions{RetryOnConflict: 3})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "POST", req.Method)
+		assert.Equal(t, "/pets/_update/tobi?retry_on_conflict=3", req.URL.RequestURI())
+		assert.Equal(t, `{"upsert":{"age":1},"script":{"source":"ctx._source.age += params.n","params":{"n":1}}}`, *body)
+		assert.Equal(t, "updated", res.Result)
+	})
+
+	t.Run("typed", func(t *testing.T) {
+		ts, req, body := docServer("6.8.23", 200, `{ "_index": "pets", "_type": "pet", "_id": "tobi", "result": "noop" }`)
+		defer ts.Close()
+
+		res, err := New(ts.URL).Update(context.Background(), "pets", "tobi", &DocUpdate{
+			Doc:         map[string]string{"species": "cat"},
+			DocAsUpsert: true,
+		}, &DocOptions{Type: "pet"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "/pets/pet/tobi/_update", req.URL.RequestURI())
+		assert.Equal(t, `{"doc":{"species":"cat"},"doc_as_upsert":true}`, *body)
+		assert.Equal(t, "noop", res.Result)
+	})
+}
+
+func TestClient_Delete(t *testing.T) {
+	ts, req, _ := docServer("7.17.0", 200, `{ "_index": "pets", "_id": "tobi", "_version": 4, "result": "deleted" }`)
+	defer ts.Close()
+
+	res, err := New(ts.URL).Delete(context.Background(), "pets", "tobi", &DocOptions{Version: 3, VersionType: "external"})
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE", req.Method)
+	assert.Equal(t, "/pets/_doc/tobi?version=3&version_type=external", req.URL.RequestURI())
+	assert.Equal(t, "deleted", res.Result)
+}
+
+func TestClient_docs_detectError(t *testing.T) {
+	var uris []string
+
+	// forbids GET / without the cluster:monitor/main privilege
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{ "error": { "type": "security_exception", "reason": "unauthorized" } }`))
+			return
+		}
+
+		uris = append(uris, r.URL.RequestURI())
+		w.Write([]byte(`{ "_index": "pets", "_id": "tobi", "found": true, "_source": {} }`))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	ctx := context.Background()
+
+	_, err := client.Get(ctx, "pets", "tobi", nil)
+	assert.NoError(t, err)
+
+	_, err = client.Get(ctx, "pets", "tobi", &DocOptions{Type: "pet"})
+	assert.NoError(t, err)
+
+	_, err = client.Update(ctx, "pets", "tobi", &DocUpdate{Doc: map[string]string{"species": "cat"}}, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"/pets/_doc/tobi", "/pets/pet/tobi", "/pets/_update/tobi"}, uris)
+}
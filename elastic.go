@@ -3,6 +3,8 @@ package elastic
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -11,6 +13,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -19,6 +22,10 @@ import (
 	"github.com/tj/go-elastic/aliases"
 )
 
+// errRequestDone is the error seen by writers of streaming bodies
+// which are not read before the request returns.
+var errRequestDone = errors.New("elastic: request done")
+
 // AWSCredentials for AWS.
 type AWSCredentials awsauth.Credentials
 
@@ -28,46 +35,52 @@ type authCredentials struct {
 	password string
 }
 
-// BulkResponse for _bulk.
-type BulkResponse struct {
-	Took   float64             `json:"took"`
-	Errors bool                `json:"errors"`
-	Items  []*BulkResponseItem `json:"items"`
-}
-
-// BulkResponseItem for _bulk.
-type BulkResponseItem struct {
-	Create *BulkResponseItemResult `json:"create,omitempty"`
-	Delete *BulkResponseItemResult `json:"delete,omitempty"`
-	Update *BulkResponseItemResult `json:"update,omitempty"`
-	Index  *BulkResponseItemResult `json:"index,omitempty"`
-}
-
-// BulkResponseItem for _bulk request responses.
-type BulkResponseItemResult struct {
-	Index   string `json:"_index"`
-	Type    string `json:"_type"`
-	ID      string `json:"_id"`
-	Version int    `json:"_version"`
-	Status  int    `json:"status"`
-	Found   bool   `json:"bool,omitempty"`
-	Error   string `json:"error,omitempty"`
-}
-
 // Client is an Elasticsearch client.
 type Client struct {
 	HTTPClient      *http.Client
-	awsCredentials  *AWSCredentials  // Credentials for AWS role
-	authCredentials *authCredentials // User/password credentials
-	URL             string           // URL to Elasticsearch cluster
+	awsCredentials  *AWSCredentials    // Credentials for AWS role
+	authCredentials *authCredentials   // User/password credentials
+	URL             string             // URL to Elasticsearch cluster, unless nodes are set
+	Retry           *RetryPolicy       // Retry policy, nil disables retries
+	OnRequest       func(RequestTrace) // Called after each request attempt
+	MaxResponseSize int64              // Maximum size of buffered response bodies, zero is unlimited, larger responses fail without retries
+	Compress        bool               // Gzip request bodies and accept gzipped responses
+	CompressMinSize int                // Minimum size of request bodies compressed
+	Mode            Mode               // API compatibility mode, detected from the cluster version by default
+	nodes           *nodePool          // Nodes requests are distributed across
+	mu              sync.Mutex         // Guards nodes, info and detection failures
+	info            *Info              // Cached info
+	detectErr       error              // Last mode detection error
+	detectAt        time.Time          // Time mode detection may be retried
 }
 
-// New client.
-func New(url string) *Client {
-	return &Client{
+// RequestTrace describes a request attempt.
+type RequestTrace struct {
+	Node     string        // Node URL which served the request
+	Method   string        // HTTP method
+	Path     string        // Request path
+	Attempt  int           // Attempt number, starting at 1
+	Status   int           // Response status, zero on error
+	Duration time.Duration // Request duration
+	Err      error         // Network error, if any
+}
+
+// New client for the given node `urls`. When several are given requests are
+// distributed across them round-robin, with dead nodes failed over.
+func New(urls ...string) *Client {
+	c := &Client{
 		HTTPClient: http.DefaultClient,
-		URL:        url,
 	}
+
+	if len(urls) > 0 {
+		c.URL = urls[0]
+	}
+
+	if len(urls) > 1 {
+		c.SetNodes(urls...)
+	}
+
+	return c
 }
 
 // SetAWSCredentials for connection to an AWS ElasticSearch instance
@@ -87,29 +100,69 @@ func (c *Client) SetAuthCredentials(username, password string) {
 
 // Bulk POST request with the given body.
 func (c *Client) Bulk(body io.Reader) error {
-	return c.Request("POST", "/_bulk", body, nil)
+	return c.BulkContext(context.Background(), body)
+}
+
+// BulkContext POST request with the given body.
+func (c *Client) BulkContext(ctx context.Context, body io.Reader) error {
+	return c.RequestContext(ctx, "POST", "/_bulk", body, nil)
 }
 
 // BulkResponse POST request with the given body and return response.
-func (c *Client) BulkResponse(body io.Reader) (res *BulkResponse, err error) {
+func (c *Client) BulkResponse(body io.Reader) (*BulkResponse, error) {
+	return c.BulkResponseContext(context.Background(), body)
+}
+
+// BulkResponseContext POST request with the given body and return response.
+func (c *Client) BulkResponseContext(ctx context.Context, body io.Reader) (res *BulkResponse, err error) {
 	res = new(BulkResponse)
-	err = c.Request("POST", "/_bulk", body, res)
+	err = c.RequestContext(ctx, "POST", "/_bulk", body, res)
 	return
 }
 
+// BulkStream POST request with the given body, calling `fn` with each item as
+// the response is decoded rather than buffering it. The returned response has
+// no Items. Only *io.PipeReader bodies are streamed, and they are not retried.
+func (c *Client) BulkStream(body io.Reader, fn func(*BulkResponseItem) error) (*BulkResponse, error) {
+	return c.BulkStreamContext(context.Background(), body, fn)
+}
+
+// BulkStreamContext POST request with the given body, calling `fn` with each item as
+// the response is decoded rather than buffering it. The returned response has no Items.
+func (c *Client) BulkStreamContext(ctx context.Context, body io.Reader, fn func(*BulkResponseItem) error) (*BulkResponse, error) {
+	s := &bulkStream{fn: fn}
+	err := c.RequestContext(ctx, "POST", "/_bulk", body, s)
+	return &s.res, err
+}
+
 // DeleteIndex deletes `index`.
 func (c *Client) DeleteIndex(index string) error {
-	return c.Request("DELETE", fmt.Sprintf("/%s", index), nil, nil)
+	return c.DeleteIndexContext(context.Background(), index)
+}
+
+// DeleteIndexContext deletes `index`.
+func (c *Client) DeleteIndexContext(ctx context.Context, index string) error {
+	return c.RequestContext(ctx, "DELETE", fmt.Sprintf("/%s", index), nil, nil)
 }
 
 // DeleteAll deletes all indexes.
 func (c *Client) DeleteAll() error {
-	return c.Request("DELETE", "/_all", nil, nil)
+	return c.DeleteAllContext(context.Background())
+}
+
+// DeleteAllContext deletes all indexes.
+func (c *Client) DeleteAllContext(ctx context.Context) error {
+	return c.RequestContext(ctx, "DELETE", "/_all", nil, nil)
 }
 
 // Aliases returns indexes and their aliases.
-func (c *Client) Aliases() (v aliases.Indexes, err error) {
-	err = c.Request("GET", "/_aliases", nil, &v)
+func (c *Client) Aliases() (aliases.Indexes, error) {
+	return c.AliasesContext(context.Background())
+}
+
+// AliasesContext returns indexes and their aliases.
+func (c *Client) AliasesContext(ctx context.Context) (v aliases.Indexes, err error) {
+	err = c.RequestContext(ctx, "GET", "/_aliases", nil, &v)
 	return
 }
 
@@ -117,7 +170,12 @@ func (c *Client) Aliases() (v aliases.Indexes, err error) {
 // such as "logs-06-01-02". For example to maintain the past week (inclusive) you might use
 // RemoveOldAliases("logs-06-01-02", "last_week", 8, time.Now()).
 func (c *Client) RemoveOldAliases(layout, alias string, n int, now time.Time) error {
-	indexes, err := c.Aliases()
+	return c.RemoveOldAliasesContext(context.Background(), layout, alias, n, now)
+}
+
+// RemoveOldAliasesContext removes `alias` from timeseries style indexes older than `n` days based on `layout`.
+func (c *Client) RemoveOldAliasesContext(ctx context.Context, layout, alias string, n int, now time.Time) error {
+	indexes, err := c.AliasesContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -127,14 +185,19 @@ func (c *Client) RemoveOldAliases(layout, alias string, n int, now time.Time) er
 		return nil
 	}
 
-	return c.Request("POST", "/_aliases", bytes.NewReader(body), nil)
+	return c.RequestContext(ctx, "POST", "/_aliases", bytes.NewReader(body), nil)
 }
 
 // RemoveOldIndexes removes indexes from timeseries style indexes older than `n` days based on `layout`
 // such as "logs-06-01-02". For example to maintain the past week (inclusive) you might use
 // RemoveOldIndexes("logs-06-01-02", 8, time.Now()).
 func (c *Client) RemoveOldIndexes(layout string, n int, now time.Time) error {
-	indexes, err := c.Aliases()
+	return c.RemoveOldIndexesContext(context.Background(), layout, n, now)
+}
+
+// RemoveOldIndexesContext removes indexes from timeseries style indexes older than `n` days based on `layout`.
+func (c *Client) RemoveOldIndexesContext(ctx context.Context, layout string, n int, now time.Time) error {
+	indexes, err := c.AliasesContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -148,26 +211,41 @@ func (c *Client) RemoveOldIndexes(layout string, n int, now time.Time) error {
 		return nil
 	}
 
-	return c.DeleteIndex(strings.Join(names, ","))
+	return c.DeleteIndexContext(ctx, strings.Join(names, ","))
 }
 
 // SearchIndex queries `index` and stores the results of `query` in `v`.
 func (c *Client) SearchIndex(index string, query interface{}, v interface{}) error {
+	return c.SearchIndexContext(context.Background(), index, query, v)
+}
+
+// SearchIndexContext queries `index` and stores the results of `query` in `v`.
+func (c *Client) SearchIndexContext(ctx context.Context, index string, query interface{}, v interface{}) error {
 	b, err := json.Marshal(query)
 	if err != nil {
 		return err
 	}
 
-	return c.Request("POST", fmt.Sprintf("/%s/_search", index), bytes.NewReader(b), v)
+	return c.RequestContext(ctx, "POST", fmt.Sprintf("/%s/_search", index), bytes.NewReader(b), v)
 }
 
 // SearchIndexString queries `index` and stores the results of `query` in `v`.
 func (c *Client) SearchIndexString(index, query string, v interface{}) error {
-	return c.Request("POST", fmt.Sprintf("/%s/_search", index), strings.NewReader(query), v)
+	return c.SearchIndexStringContext(context.Background(), index, query, v)
+}
+
+// SearchIndexStringContext queries `index` and stores the results of `query` in `v`.
+func (c *Client) SearchIndexStringContext(ctx context.Context, index, query string, v interface{}) error {
+	return c.RequestContext(ctx, "POST", fmt.Sprintf("/%s/_search", index), strings.NewReader(query), v)
 }
 
 // SearchIndexTemplate queries `index` with `tmpl` string and stores the results in `v`.
 func (c *Client) SearchIndexTemplate(index, tmpl string, data interface{}, v interface{}) error {
+	return c.SearchIndexTemplateContext(context.Background(), index, tmpl, data, v)
+}
+
+// SearchIndexTemplateContext queries `index` with `tmpl` string and stores the results in `v`.
+func (c *Client) SearchIndexTemplateContext(ctx context.Context, index, tmpl string, data interface{}, v interface{}) error {
 	var buf bytes.Buffer
 
 	t, err := template.New("main").Parse(tmpl)
@@ -179,28 +257,204 @@ func (c *Client) SearchIndexTemplate(index, tmpl string, data interface{}, v int
 		return err
 	}
 
-	return c.SearchIndexString(index, buf.String(), v)
+	return c.SearchIndexStringContext(ctx, index, buf.String(), v)
 }
 
 // RefreshIndex refreshes `index`.
 func (c *Client) RefreshIndex(index string) error {
-	return c.Request("POST", fmt.Sprintf("/%s/_refresh", index), nil, nil)
+	return c.RefreshIndexContext(context.Background(), index)
+}
+
+// RefreshIndexContext refreshes `index`.
+func (c *Client) RefreshIndexContext(ctx context.Context, index string) error {
+	return c.RequestContext(ctx, "POST", fmt.Sprintf("/%s/_refresh", index), nil, nil)
 }
 
 // RefreshAll refreshes all indexes.
 func (c *Client) RefreshAll() error {
-	return c.Request("POST", "/_refresh", nil, nil)
+	return c.RefreshAllContext(context.Background())
+}
+
+// RefreshAllContext refreshes all indexes.
+func (c *Client) RefreshAllContext(ctx context.Context) error {
+	return c.RequestContext(ctx, "POST", "/_refresh", nil, nil)
 }
 
 // Request performs a request against `url` storing the results as `v` when non-nil.
 func (c *Client) Request(method, path string, body io.Reader, v interface{}) error {
-	req, err := http.NewRequest(method, c.URL+path, body)
+	return c.RequestContext(context.Background(), method, path, body, v)
+}
+
+// RequestContext performs a request against `url` storing the results as `v` when non-nil.
+// Requests are retried according to the client's RetryPolicy, if any, and fail over to
+// other nodes on connection errors, see RetryPolicy.RetryError, when the client has several.
+// Only *io.PipeReader bodies are streamed rather than buffered, so they are neither retried
+// nor failed over, and they are closed when the request returns.
+func (c *Client) RequestContext(ctx context.Context, method, path string, body io.Reader, v interface{}) error {
+	var payload []byte
+	header := make(http.Header)
+
+	if c.Mode == ModeCompatible {
+		header.Set("Content-Type", mediaType(path))
+		header.Set("Accept", mediaType(path))
+	}
+	_, streaming := body.(*io.PipeReader)
+	replay := body != nil && !streaming && (c.Retry != nil || c.failovers() > 0 || c.Compress)
+
+	if replay {
+		b, err := readBody(body)
+		if err != nil {
+			return err
+		}
+		payload = b
+	}
+
+	if c.Compress && body != nil {
+		switch {
+		case streaming:
+			body = gzipPipe(body)
+			header.Set("Content-Encoding", "gzip")
+		case len(payload) >= c.CompressMinSize:
+			b, err := gzipBytes(payload)
+			if err != nil {
+				return err
+			}
+			payload = b
+			header.Set("Content-Encoding", "gzip")
+		}
+	}
+
+	// unblock the writer of a streaming body when returning before it is read
+	if r, ok := body.(*io.PipeReader); ok {
+		defer r.CloseWithError(errRequestDone)
+	}
+
+	for attempt, failover := 1, 0; ; {
+		if replay {
+			body = bytes.NewReader(payload)
+		}
+
+		url := c.URL
+		n := c.node(ctx)
+		if n != nil {
+			url = n.url
+		}
+
+		req, err := c.newRequest(ctx, method, url, path, header, body)
+		if err != nil {
+			return err
+		}
+
+		start := time.Now()
+		res, err := c.HTTPClient.Do(req)
+
+		if c.OnRequest != nil {
+			t := RequestTrace{
+				Node:     url,
+				Method:   method,
+				Path:     path,
+				Attempt:  attempt + failover,
+				Duration: time.Since(start),
+				Err:      err,
+			}
+			if res != nil {
+				t.Status = res.StatusCode
+			}
+			c.OnRequest(t)
+		}
+
+		if ctx.Err() == nil {
+			c.release(n, err)
+		}
+
+		if err != nil && ctx.Err() == nil && c.Retry.retryError(err) && failover < c.failovers() && (body == nil || replay) {
+			failover++
+			continue
+		}
+
+		if delay, ok := c.Retry.retry(ctx, attempt, res, err); ok && (body == nil || replay) {
+			if res != nil {
+				res.Body.Close()
+			}
+			if err := Sleep(ctx, delay); err != nil {
+				return err
+			}
+			attempt++
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return c.decode(res, url, v)
+	}
+}
+
+// decode response `res` from node `url` into `v`, returning an
+// *Error for non-2xx responses. The response body is closed.
+func (c *Client) decode(res *http.Response, url string, v interface{}) error {
+	defer res.Body.Close()
+
+	r := io.Reader(res.Body)
+
+	if res.Header.Get("Content-Encoding") == "gzip" {
+		z, err := gzip.NewReader(res.Body)
+		if err != nil {
+			return err
+		}
+		r = z
+	}
+
+	if s, ok := v.(streamer); ok && res.StatusCode < 300 {
+		return s.stream(r)
+	}
+
+	b, err := c.readResponse(r)
 	if err != nil {
 		return err
 	}
 
+	if res.StatusCode >= 300 {
+		e := newError(res.StatusCode, b)
+		e.Node = url
+		return e
+	}
+
+	if v != nil {
+		return json.Unmarshal(b, v)
+	}
+
+	return nil
+}
+
+// failovers returns the number of other nodes a request may fail over to.
+func (c *Client) failovers() int {
+	p := c.pool()
+	if p == nil {
+		return 0
+	}
+
+	return p.Len() - 1
+}
+
+// newRequest returns a request against node `url`, signed when necessary.
+func (c *Client) newRequest(ctx context.Context, method, url, path string, header http.Header, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url+path, body)
+	if err != nil {
+		return nil, err
+	}
+
 	req.Header.Set("Content-Type", "application/json")
 
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	if c.Compress {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+
 	if c.authCredentials != nil {
 		credentials := fmt.Sprintf("%s:%s", c.authCredentials.username, c.authCredentials.password)
 		b64credentials := base64.StdEncoding.EncodeToString([]byte(credentials))
@@ -208,28 +462,27 @@ func (c *Client) Request(method, path string, body io.Reader, v interface{}) err
 	} else if c.awsCredentials != nil {
 		req = awsauth.Sign4(req, awsauth.Credentials(*c.awsCredentials))
 		if req == nil {
-			return errors.New("elastic: error signing request")
+			return nil, errors.New("elastic: error signing request")
 		}
 	}
 
-	res, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
+	return req, nil
+}
 
-	b, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return err
+// readResponse reads response body `r`, up to the client's MaxResponseSize.
+func (c *Client) readResponse(r io.Reader) ([]byte, error) {
+	if c.MaxResponseSize <= 0 {
+		return ioutil.ReadAll(r)
 	}
 
-	if res.StatusCode >= 300 {
-		return fmt.Errorf("%s: %s", res.Status, b)
+	b, err := ioutil.ReadAll(io.LimitReader(r, c.MaxResponseSize+1))
+	if err != nil {
+		return nil, err
 	}
 
-	if v != nil {
-		return json.Unmarshal(b, v)
+	if int64(len(b)) > c.MaxResponseSize {
+		return nil, ErrResponseTooLarge
 	}
 
-	return nil
+	return b, nil
 }
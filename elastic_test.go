@@ -1,7 +1,13 @@
 package elastic
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"regexp"
 	"sort"
 	"strings"
 	"testing"
@@ -37,6 +43,18 @@ var seriesDocs = `{ "index": { "_index": "series-16-01-20", "_type": "series" }}
 // Elastic endpoint.
 var endpoint = os.Getenv("ES_ADDR")
 
+// typeless returns bulk `docs` without mapping types when unsupported by the cluster.
+func typeless(t *testing.T, client *Client, docs string) io.Reader {
+	ok, err := client.Typeless(context.Background())
+	assert.NoError(t, err, "detecting mode")
+
+	if ok {
+		docs = regexp.MustCompile(`, "_type": "\w+"`).ReplaceAllString(docs, "")
+	}
+
+	return strings.NewReader(docs)
+}
+
 func newClient(t *testing.T) *Client {
 	client := New(endpoint)
 	_ = client.DeleteAll()
@@ -46,7 +64,7 @@ func newClient(t *testing.T) *Client {
 
 func TestClient_Bulk(t *testing.T) {
 	client := newClient(t)
-	assert.NoError(t, client.Bulk(strings.NewReader(docs)))
+	assert.NoError(t, client.Bulk(typeless(t, client, docs)))
 }
 
 func TestClient_Bulk_error(t *testing.T) {
@@ -57,7 +75,7 @@ func TestClient_Bulk_error(t *testing.T) {
 
 func TestClient_SearchIndexString(t *testing.T) {
 	client := newClient(t)
-	assert.NoError(t, client.Bulk(strings.NewReader(docs)))
+	assert.NoError(t, client.Bulk(typeless(t, client, docs)))
 
 	assert.NoError(t, client.RefreshAll(), "refreshing")
 
@@ -93,7 +111,7 @@ func TestClient_SearchIndexString(t *testing.T) {
 
 func TestClient_SearchIndex(t *testing.T) {
 	client := newClient(t)
-	assert.NoError(t, client.Bulk(strings.NewReader(docs)))
+	assert.NoError(t, client.Bulk(typeless(t, client, docs)))
 
 	assert.NoError(t, client.RefreshAll(), "refreshing")
 
@@ -131,7 +149,7 @@ func TestClient_SearchIndex(t *testing.T) {
 
 func TestClient_SearchIndexTemplate(t *testing.T) {
 	client := newClient(t)
-	assert.NoError(t, client.Bulk(strings.NewReader(docs)))
+	assert.NoError(t, client.Bulk(typeless(t, client, docs)))
 
 	assert.NoError(t, client.RefreshAll(), "refreshing")
 
@@ -176,7 +194,7 @@ func TestClient_Aliases(t *testing.T) {
 	assert.NoError(t, err, "error fetching aliases")
 	assert.Empty(t, indexes, "aliases should be empty")
 
-	assert.NoError(t, client.Bulk(strings.NewReader(seriesDocs)))
+	assert.NoError(t, client.Bulk(typeless(t, client, seriesDocs)))
 	assert.NoError(t, client.RefreshAll(), "refreshing")
 
 	indexes, err = client.Aliases()
@@ -190,7 +208,7 @@ func TestClient_Aliases(t *testing.T) {
 func TestClient_RemoveOldIndexes(t *testing.T) {
 	client := newClient(t)
 
-	assert.NoError(t, client.Bulk(strings.NewReader(seriesDocs)))
+	assert.NoError(t, client.Bulk(typeless(t, client, seriesDocs)))
 	assert.NoError(t, client.RefreshAll(), "refreshing")
 
 	now, err := time.Parse("2006-01-02", "2016-01-23")
@@ -206,3 +224,16 @@ func TestClient_RemoveOldIndexes(t *testing.T) {
 	sort.Strings(names)
 	assert.Equal(t, []string{"series-16-01-22", "series-16-01-23"}, names)
 }
+
+func TestClient_RequestContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := New(ts.URL).RefreshAllContext(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "deadline exceeded")
+}
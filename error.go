@@ -0,0 +1,126 @@
+package elastic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrorCause is an Elasticsearch error cause.
+type ErrorCause struct {
+	Type      string      `json:"type"`
+	Reason    string      `json:"reason"`
+	Index     string      `json:"index,omitempty"`
+	IndexUUID string      `json:"index_uuid,omitempty"`
+	Shard     string      `json:"shard,omitempty"`
+	CausedBy  *ErrorCause `json:"caused_by,omitempty"`
+}
+
+// UnmarshalJSON implementation accepting the string
+// errors of Elasticsearch 1.x as the reason.
+func (e *ErrorCause) UnmarshalJSON(b []byte) error {
+	var reason string
+	if err := json.Unmarshal(b, &reason); err == nil {
+		*e = ErrorCause{Reason: reason}
+		return nil
+	}
+
+	type errorCause ErrorCause
+	return json.Unmarshal(b, (*errorCause)(e))
+}
+
+// Error implementation.
+func (e *ErrorCause) Error() string {
+	s := e.Reason
+
+	if e.Type != "" {
+		s = fmt.Sprintf("%s: %s", e.Type, e.Reason)
+	}
+
+	if e.CausedBy != nil {
+		s = fmt.Sprintf("%s: %s", s, e.CausedBy.Error())
+	}
+
+	return s
+}
+
+// Error is returned for non-2xx responses.
+type Error struct {
+	Status    int          // HTTP status code
+	Type      string       // Elasticsearch error type such as "index_not_found_exception"
+	Reason    string       // Elasticsearch error reason
+	RootCause []ErrorCause // Elasticsearch root causes
+	Body      []byte       // Raw response body
+	Node      string       // Node URL which served the request
+}
+
+// newError returns an Error for `status` parsing the response `body` when possible.
+func newError(status int, body []byte) *Error {
+	e := &Error{
+		Status: status,
+		Body:   body,
+	}
+
+	var res struct {
+		Error json.RawMessage `json:"error"`
+	}
+
+	if err := json.Unmarshal(body, &res); err != nil || len(res.Error) == 0 {
+		return e
+	}
+
+	var cause ErrorCause
+	if err := json.Unmarshal(res.Error, &cause); err != nil {
+		return e
+	}
+
+	e.Type = cause.Type
+	e.Reason = cause.Reason
+
+	var detail struct {
+		RootCause []ErrorCause `json:"root_cause"`
+	}
+
+	// Elasticsearch 1.x responds with a string, without root causes
+	if err := json.Unmarshal(res.Error, &detail); err == nil {
+		e.RootCause = detail.RootCause
+	}
+
+	return e
+}
+
+// Error implementation.
+func (e *Error) Error() string {
+	status := fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+
+	switch {
+	case e.Type != "":
+		return fmt.Sprintf("elastic: %s: %s: %s", status, e.Type, e.Reason)
+	case e.Reason != "":
+		return fmt.Sprintf("elastic: %s: %s", status, e.Reason)
+	default:
+		return fmt.Sprintf("elastic: %s: %s", status, e.Body)
+	}
+}
+
+// IsNotFound returns true if `err` is a 404 response.
+func IsNotFound(err error) bool {
+	return IsStatus(err, http.StatusNotFound)
+}
+
+// IsConflict returns true if `err` is a 409 response.
+func IsConflict(err error) bool {
+	return IsStatus(err, http.StatusConflict)
+}
+
+// IsTooManyRequests returns true if `err` is a 429 response.
+func IsTooManyRequests(err error) bool {
+	return IsStatus(err, http.StatusTooManyRequests)
+}
+
+// IsStatus returns true if `err` is a response with the given `status`.
+func IsStatus(err error, status int) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Status == status
+}
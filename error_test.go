@@ -0,0 +1,46 @@
+package elastic
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	body := `{"error":{"root_cause":[{"type":"index_not_found_exception","reason":"no such index","index":"pets"}],"type":"index_not_found_exception","reason":"no such index","index":"pets"},"status":404}`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, body)
+	}))
+	defer ts.Close()
+
+	err := New(ts.URL).DeleteIndex("pets")
+	assert.Error(t, err)
+	assert.True(t, IsNotFound(fmt.Errorf("wrapped: %w", err)), "not found")
+	assert.False(t, IsConflict(err), "conflict")
+
+	e := err.(*Error)
+	assert.Equal(t, 404, e.Status)
+	assert.Equal(t, "index_not_found_exception", e.Type)
+	assert.Equal(t, "no such index", e.Reason)
+	assert.Equal(t, "pets", e.RootCause[0].Index)
+	assert.Equal(t, body, string(e.Body))
+	assert.Equal(t, "elastic: 404 Not Found: index_not_found_exception: no such index", e.Error())
+}
+
+func TestError_string(t *testing.T) {
+	e := newError(http.StatusConflict, []byte(`{"error":"VersionConflictEngineException[...]","status":409}`))
+	assert.True(t, IsConflict(e), "conflict")
+	assert.Equal(t, "VersionConflictEngineException[...]", e.Reason)
+	assert.Equal(t, "elastic: 409 Conflict: VersionConflictEngineException[...]", e.Error())
+}
+
+func TestError_raw(t *testing.T) {
+	e := newError(http.StatusTooManyRequests, []byte(`slow down`))
+	assert.True(t, IsTooManyRequests(e), "too many requests")
+	assert.Equal(t, "elastic: 429 Too Many Requests: slow down", e.Error())
+}
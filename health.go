@@ -0,0 +1,165 @@
+package elastic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HealthOptions configures ClusterHealth.
+type HealthOptions struct {
+	Index                     string        // Index names, comma-delimited, defaults to all
+	Level                     string        // Level of detail, "cluster", "indices" or "shards"
+	WaitForStatus             string        // Wait for status "yellow" or "green"
+	WaitForActiveShards       string        // Wait for this many active shards, or "all"
+	WaitForNoRelocatingShards bool          // Wait for no relocating shards
+	Timeout                   time.Duration // Time to wait, defaults to 30s
+}
+
+// Health of a cluster.
+type Health struct {
+	ClusterName                 string                  `json:"cluster_name"`
+	Status                      string                  `json:"status"`
+	TimedOut                    bool                    `json:"timed_out"`
+	NumberOfNodes               int                     `json:"number_of_nodes"`
+	NumberOfDataNodes           int                     `json:"number_of_data_nodes"`
+	ActivePrimaryShards         int                     `json:"active_primary_shards"`
+	ActiveShards                int                     `json:"active_shards"`
+	RelocatingShards            int                     `json:"relocating_shards"`
+	InitializingShards          int                     `json:"initializing_shards"`
+	UnassignedShards            int                     `json:"unassigned_shards"`
+	DelayedUnassignedShards     int                     `json:"delayed_unassigned_shards"`
+	NumberOfPendingTasks        int                     `json:"number_of_pending_tasks"`
+	NumberOfInFlightFetch       int                     `json:"number_of_in_flight_fetch"`
+	TaskMaxWaitingInQueueMillis int64                   `json:"task_max_waiting_in_queue_millis"`
+	ActiveShardsPercent         float64                 `json:"active_shards_percent_as_number"`
+	Indices                     map[string]*IndexHealth `json:"indices,omitempty"`
+}
+
+// IndexHealth is the health of an index, with level "indices" or "shards".
+type IndexHealth struct {
+	Status              string                  `json:"status"`
+	NumberOfShards      int                     `json:"number_of_shards"`
+	NumberOfReplicas    int                     `json:"number_of_replicas"`
+	ActivePrimaryShards int                     `json:"active_primary_shards"`
+	ActiveShards        int                     `json:"active_shards"`
+	RelocatingShards    int                     `json:"relocating_shards"`
+	InitializingShards  int                     `json:"initializing_shards"`
+	UnassignedShards    int                     `json:"unassigned_shards"`
+	Shards              map[string]*ShardHealth `json:"shards,omitempty"`
+}
+
+// ShardHealth is the health of a shard, with level "shards".
+type ShardHealth struct {
+	Status             string `json:"status"`
+	PrimaryActive      bool   `json:"primary_active"`
+	ActiveShards       int    `json:"active_shards"`
+	RelocatingShards   int    `json:"relocating_shards"`
+	InitializingShards int    `json:"initializing_shards"`
+	UnassignedShards   int    `json:"unassigned_shards"`
+}
+
+// ClusterHealth returns the health of the cluster, or of the indexes given. When
+// waiting times out the health is returned with TimedOut set, rather than an error.
+func (c *Client) ClusterHealth(ctx context.Context, opts HealthOptions) (*Health, error) {
+	path := "/_cluster/health"
+
+	if opts.Index != "" {
+		path += "/" + opts.Index
+	}
+
+	params := url.Values{}
+
+	if opts.Level != "" {
+		params.Set("level", opts.Level)
+	}
+
+	if opts.WaitForStatus != "" {
+		params.Set("wait_for_status", opts.WaitForStatus)
+	}
+
+	if opts.WaitForActiveShards != "" {
+		params.Set("wait_for_active_shards", opts.WaitForActiveShards)
+	}
+
+	if opts.WaitForNoRelocatingShards {
+		params.Set("wait_for_no_relocating_shards", "true")
+	}
+
+	if opts.Timeout > 0 {
+		params.Set("timeout", keepAlive(opts.Timeout))
+	}
+
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	h := new(Health)
+	err := c.RequestContext(ctx, "GET", path, nil, h)
+
+	var e *Error
+	if errors.As(err, &e) && e.Status == http.StatusRequestTimeout {
+		if err := json.Unmarshal(e.Body, h); err == nil {
+			return h, nil
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// WaitForHealthy blocks until `index`, or the cluster when empty, has at least `status`
+// "yellow" or "green", or `ctx` is done. Waiting is performed by the cluster, polling
+// on an interval when it times out, is unreachable, or disregards wait_for_status.
+func (c *Client) WaitForHealthy(ctx context.Context, index, status string) (*Health, error) {
+	want, ok := healthStatuses[status]
+	if !ok {
+		return nil, fmt.Errorf("elastic: invalid health status %q", status)
+	}
+
+	opts := HealthOptions{
+		Index:         index,
+		WaitForStatus: status,
+		Timeout:       10 * time.Second,
+	}
+
+	for {
+		if deadline, ok := ctx.Deadline(); ok {
+			if d := time.Until(deadline); d < opts.Timeout {
+				opts.Timeout = d
+			}
+		}
+
+		h, err := c.ClusterHealth(ctx, opts)
+
+		if err == nil && healthStatuses[h.Status] >= want {
+			return h, nil
+		}
+
+		var e *Error
+		if errors.As(err, &e) && e.Status < 500 && e.Status != http.StatusTooManyRequests {
+			return nil, err
+		}
+
+		if err := Sleep(ctx, healthInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// healthInterval is the interval between health polls.
+var healthInterval = time.Second
+
+// healthStatuses ordered from least to most healthy.
+var healthStatuses = map[string]int{
+	"red":    1,
+	"yellow": 2,
+	"green":  3,
+}
@@ -0,0 +1,135 @@
+package elastic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var health = `{
+  "cluster_name" : "logs",
+  "status" : "%s",
+  "timed_out" : %t,
+  "number_of_nodes" : 3,
+  "number_of_data_nodes" : 3,
+  "active_primary_shards" : 5,
+  "active_shards" : 10,
+  "relocating_shards" : 0,
+  "initializing_shards" : 0,
+  "unassigned_shards" : 0,
+  "delayed_unassigned_shards" : 0,
+  "number_of_pending_tasks" : 0,
+  "number_of_in_flight_fetch" : 0,
+  "task_max_waiting_in_queue_millis" : 0,
+  "active_shards_percent_as_number" : 100.0,
+  "indices" : {
+    "pets" : {
+      "status" : "%[1]s",
+      "number_of_shards" : 1,
+      "number_of_replicas" : 1,
+      "active_primary_shards" : 1,
+      "active_shards" : 2,
+      "relocating_shards" : 0,
+      "initializing_shards" : 0,
+      "unassigned_shards" : 0
+    }
+  }
+}`
+
+func TestClient_ClusterHealth(t *testing.T) {
+	var query string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_cluster/health/pets", r.URL.Path)
+		query = r.URL.RawQuery
+		w.WriteHeader(http.StatusRequestTimeout)
+		fmt.Fprintf(w, health, "yellow", true)
+	}))
+	defer ts.Close()
+
+	h, err := New(ts.URL).ClusterHealth(context.Background(), HealthOptions{
+		Index:                     "pets",
+		Level:                     "indices",
+		WaitForStatus:             "green",
+		WaitForActiveShards:       "all",
+		WaitForNoRelocatingShards: true,
+		Timeout:                   5 * time.Second,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "level=indices&timeout=5000ms&wait_for_active_shards=all&wait_for_no_relocating_shards=true&wait_for_status=green", query)
+	assert.Equal(t, "logs", h.ClusterName)
+	assert.Equal(t, "yellow", h.Status)
+	assert.True(t, h.TimedOut)
+	assert.Equal(t, 3, h.NumberOfNodes)
+	assert.Equal(t, float64(100), h.ActiveShardsPercent)
+	assert.Equal(t, 2, h.Indices["pets"].ActiveShards)
+}
+
+func TestClient_WaitForHealthy(t *testing.T) {
+	healthInterval = time.Millisecond
+
+	t.Run("polling", func(t *testing.T) {
+		var requests int
+
+		// disregards wait_for_status, turning green on the third request
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			switch requests {
+			case 1:
+				w.WriteHeader(http.StatusServiceUnavailable)
+			case 2:
+				fmt.Fprintf(w, health, "red", false)
+			default:
+				fmt.Fprintf(w, health, "green", false)
+			}
+		}))
+		defer ts.Close()
+
+		h, err := New(ts.URL).WaitForHealthy(context.Background(), "", "yellow")
+		assert.NoError(t, err)
+		assert.Equal(t, "green", h.Status)
+		assert.Equal(t, 3, requests)
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "green", r.URL.Query().Get("wait_for_status"))
+
+			ms, err := strconv.Atoi(strings.TrimSuffix(r.URL.Query().Get("timeout"), "ms"))
+			assert.NoError(t, err)
+			assert.True(t, ms <= 100, "timeout within deadline")
+			w.WriteHeader(http.StatusRequestTimeout)
+			fmt.Fprintf(w, health, "yellow", true)
+		}))
+		defer ts.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := New(ts.URL).WaitForHealthy(ctx, "pets", "green")
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		_, err := New("http://localhost:9200").WaitForHealthy(context.Background(), "", "blue")
+		assert.EqualError(t, err, `elastic: invalid health status "blue"`)
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer ts.Close()
+
+		_, err := New(ts.URL).WaitForHealthy(context.Background(), "", "green")
+		assert.True(t, IsStatus(err, http.StatusUnauthorized))
+	})
+}
@@ -0,0 +1,139 @@
+package elastic
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Info about a cluster, as reported by the node serving GET /.
+type Info struct {
+	Name        string      `json:"name"`         // Node name
+	ClusterName string      `json:"cluster_name"` // Cluster name
+	ClusterUUID string      `json:"cluster_uuid"` // Cluster UUID
+	Version     VersionInfo `json:"version"`      // Version details
+	Tagline     string      `json:"tagline"`      // Tagline
+}
+
+// VersionInfo is the version details of a cluster.
+type VersionInfo struct {
+	Number        string `json:"number"`         // Version number such as "7.10.2"
+	Distribution  string `json:"distribution"`   // Distribution, "elasticsearch" or "opensearch"
+	BuildFlavor   string `json:"build_flavor"`   // Build flavor such as "default" or "oss"
+	BuildType     string `json:"build_type"`     // Build type such as "docker"
+	BuildHash     string `json:"build_hash"`     // Build commit hash
+	BuildDate     string `json:"build_date"`     // Build date
+	LuceneVersion string `json:"lucene_version"` // Lucene version
+}
+
+// Info returns information about the cluster, caching it for Version.
+func (c *Client) Info(ctx context.Context) (*Info, error) {
+	info := new(Info)
+
+	if err := c.RequestContext(ctx, "GET", "/", nil, info); err != nil {
+		return nil, err
+	}
+
+	if info.Version.Distribution == "" {
+		info.Version.Distribution = "elasticsearch"
+	}
+
+	c.mu.Lock()
+	c.info = info
+	c.mu.Unlock()
+
+	return info, nil
+}
+
+// Ping returns an error if the cluster is unreachable.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.RequestContext(ctx, "HEAD", "/", nil, nil)
+}
+
+// Version returns the version of the cluster, requesting Info
+// unless it has been cached.
+func (c *Client) Version(ctx context.Context) (Version, error) {
+	info, err := c.cachedInfo(ctx)
+	if err != nil {
+		return Version{}, err
+	}
+
+	return ParseVersion(info.Version.Number)
+}
+
+// cachedInfo returns the cached info, requesting it when necessary.
+func (c *Client) cachedInfo(ctx context.Context) (*Info, error) {
+	c.mu.Lock()
+	info := c.info
+	c.mu.Unlock()
+
+	if info != nil {
+		return info, nil
+	}
+
+	return c.Info(ctx)
+}
+
+// Version is a semantic version.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion parses version `s` such as "7.10.2" or "8.0.0-rc1",
+// ignoring any pre-release or build suffix.
+func ParseVersion(s string) (Version, error) {
+	var v Version
+
+	n := strings.IndexAny(s, "-+")
+	if n != -1 {
+		s = s[:n]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, fmt.Errorf("elastic: invalid version %q", s)
+	}
+
+	fields := []*int{&v.Major, &v.Minor, &v.Patch}
+
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("elastic: invalid version %q", s)
+		}
+		*fields[i] = n
+	}
+
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 when `v` is less than,
+// equal to, or greater than version `w`.
+func (v Version) Compare(w Version) int {
+	a := []int{v.Major, v.Minor, v.Patch}
+	b := []int{w.Major, w.Minor, w.Patch}
+
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// AtLeast returns true if `v` is greater than or equal to `major.minor`.
+func (v Version) AtLeast(major, minor int) bool {
+	return v.Compare(Version{Major: major, Minor: minor}) >= 0
+}
+
+// String implementation.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
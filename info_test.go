@@ -0,0 +1,100 @@
+package elastic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var info = `{
+  "name" : "es01",
+  "cluster_name" : "logs",
+  "cluster_uuid" : "Ilu7LsJuR3mLtDH3hcw9Kg",
+  "version" : {
+    "number" : "8.11.1",
+    "build_flavor" : "default",
+    "build_type" : "docker",
+    "build_hash" : "6f9ff581fbcde658e6f69d6ce03050f060d1fd0c",
+    "build_date" : "2023-11-11T10:05:59.421038163Z",
+    "lucene_version" : "9.8.0"
+  },
+  "tagline" : "You Know, for Search"
+}`
+
+func TestClient_Info(t *testing.T) {
+	var requests int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method == "GET" {
+			w.Write([]byte(info))
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	ctx := context.Background()
+
+	assert.NoError(t, client.Ping(ctx))
+
+	i, err := client.Info(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "logs", i.ClusterName)
+	assert.Equal(t, "Ilu7LsJuR3mLtDH3hcw9Kg", i.ClusterUUID)
+	assert.Equal(t, "8.11.1", i.Version.Number)
+	assert.Equal(t, "elasticsearch", i.Version.Distribution)
+	assert.Equal(t, "default", i.Version.BuildFlavor)
+	assert.Equal(t, "9.8.0", i.Version.LuceneVersion)
+
+	v, err := client.Version(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, Version{8, 11, 1}, v)
+	assert.True(t, v.AtLeast(7, 10))
+	assert.Equal(t, 2, requests, "cached")
+}
+
+func TestClient_Ping(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	err := New(ts.URL).Ping(context.Background())
+	assert.True(t, IsStatus(err, http.StatusServiceUnavailable))
+}
+
+func TestParseVersion(t *testing.T) {
+	cases := []struct {
+		s string
+		v Version
+	}{
+		{"2.1.1", Version{2, 1, 1}},
+		{"7.10.2-SNAPSHOT", Version{7, 10, 2}},
+		{"8.0.0-rc1", Version{8, 0, 0}},
+		{"2.11", Version{2, 11, 0}},
+	}
+
+	for _, c := range cases {
+		v, err := ParseVersion(c.s)
+		assert.NoError(t, err, c.s)
+		assert.Equal(t, c.v, v, c.s)
+	}
+
+	_, err := ParseVersion("")
+	assert.Error(t, err)
+
+	_, err = ParseVersion("7.x")
+	assert.Error(t, err)
+}
+
+func TestVersion_Compare(t *testing.T) {
+	assert.Equal(t, 0, Version{7, 10, 2}.Compare(Version{7, 10, 2}))
+	assert.Equal(t, -1, Version{6, 8, 23}.Compare(Version{7, 0, 0}))
+	assert.Equal(t, 1, Version{7, 10, 0}.Compare(Version{7, 9, 3}))
+	assert.Equal(t, -1, Version{7, 10, 2}.Compare(Version{7, 10, 3}))
+	assert.False(t, Version{7, 9, 3}.AtLeast(7, 10))
+	assert.Equal(t, "7.9.3", Version{7, 9, 3}.String())
+}
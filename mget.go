@@ -0,0 +1,61 @@
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// MultiGetItem is a document requested by MultiGet.
+type MultiGetItem struct {
+	Index   string   `json:"_index"`            // Index name
+	ID      string   `json:"_id"`               // Document ID
+	Routing string   `json:"routing,omitempty"` // Routing value
+	Source  []string `json:"_source,omitempty"` // Source fields included, defaults to all
+}
+
+// MultiGet returns the documents `items`, in the same order. Missing documents
+// are not Found, and those which failed, for example due to a missing index,
+// have their Error set.
+func (c *Client) MultiGet(ctx context.Context, items []MultiGetItem) ([]*Doc, error) {
+	b, err := json.Marshal(map[string][]MultiGetItem{
+		"docs": items,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Docs []*Doc `json:"docs"`
+	}
+
+	if err := c.RequestContext(ctx, "POST", "/_mget", bytes.NewReader(b), &res); err != nil {
+		return nil, err
+	}
+
+	if len(res.Docs) != len(items) {
+		return nil, fmt.Errorf("elastic: expected %d docs in response, got %d", len(items), len(res.Docs))
+	}
+
+	return res.Docs, nil
+}
+
+// Docs decodes the sources of `docs` as T, in the same order,
+// leaving the zero value for documents which were not found.
+func Docs[T any](docs []*Doc) ([]T, error) {
+	v := make([]T, len(docs))
+
+	for i, doc := range docs {
+		if !doc.Found {
+			continue
+		}
+
+		if err := doc.Decode(&v[i]); err != nil {
+			return nil, fmt.Errorf("elastic: decoding doc %q: %w", doc.ID, err)
+		}
+	}
+
+	return v, nil
+}
@@ -0,0 +1,66 @@
+package elastic
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var mgetResponse = `{
+  "docs": [
+    { "_index": "pets", "_id": "tobi", "_version": 1, "found": true, "_source": { "name": "Tobi", "species": "ferret" } },
+    { "_index": "pets", "_id": "nobody", "found": false },
+    {
+      "_index": "missing", "_id": "loki",
+      "error": { "type": "index_not_found_exception", "reason": "no such index [missing]", "index": "missing" }
+    },
+    { "_index": "pets", "_id": "manny", "_version": 3, "found": true, "_source": { "name": "Manny", "species": "cat" } }
+  ]
+}`
+
+func TestClient_MultiGet(t *testing.T) {
+	var body string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_mget", r.URL.Path)
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(mgetResponse))
+	}))
+	defer ts.Close()
+
+	docs, err := New(ts.URL).MultiGet(context.Background(), []MultiGetItem{
+		{Index: "pets", ID: "tobi", Source: []string{"name", "species"}},
+		{Index: "pets", ID: "nobody"},
+		{Index: "missing", ID: "loki"},
+		{Index: "pets", ID: "manny", Routing: "cats"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"docs":[{"_index":"pets","_id":"tobi","_source":["name","species"]},{"_index":"pets","_id":"nobody"},{"_index":"missing","_id":"loki"},{"_index":"pets","_id":"manny","routing":"cats"}]}`, body)
+
+	assert.Len(t, docs, 4)
+	assert.True(t, docs[0].Found)
+	assert.False(t, docs[1].Found)
+	assert.Nil(t, docs[1].Error)
+	assert.EqualError(t, docs[2].Error, "index_not_found_exception: no such index [missing]")
+	assert.Equal(t, int64(3), docs[3].Version)
+
+	pets, err := Docs[pet](docs)
+	assert.NoError(t, err)
+	assert.Equal(t, []pet{{"Tobi", "ferret"}, {}, {}, {"Manny", "cat"}}, pets)
+}
+
+func TestClient_MultiGet_mismatch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{ "docs": [] }`))
+	}))
+	defer ts.Close()
+
+	_, err := New(ts.URL).MultiGet(context.Background(), []MultiGetItem{{Index: "pets", ID: "tobi"}})
+	assert.EqualError(t, err, "elastic: expected 1 docs in response, got 0")
+}
@@ -0,0 +1,101 @@
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// SearchRequest is a search performed by Search or MultiSearch.
+type SearchRequest struct {
+	Index        string      `json:"index,omitempty"`         // Index names, comma-delimited
+	Body         interface{} `json:"-"`                       // Search body such as a query.SearchSource, defaults to matching all
+	Routing      string      `json:"routing,omitempty"`       // Routing value
+	Preference   string      `json:"preference,omitempty"`    // Preference such as "_local"
+	SearchType   string      `json:"search_type,omitempty"`   // Search type such as "dfs_query_then_fetch"
+	RequestCache *bool       `json:"request_cache,omitempty"` // Enable or disable the request cache
+}
+
+// MultiSearchOptions configures MultiSearch.
+type MultiSearchOptions struct {
+	MaxConcurrentSearches int // Maximum number of concurrent searches, defaults to the cluster's default
+}
+
+// MultiSearchResponse is the response to a request made by MultiSearch.
+type MultiSearchResponse struct {
+	Result *SearchResult // Search result, unless it failed
+	Err    error         // Error, as an *Error, if it failed
+}
+
+// MultiSearch performs `requests` in a single request, returning
+// one response per request in the same order.
+func (c *Client) MultiSearch(ctx context.Context, requests []SearchRequest, opts MultiSearchOptions) ([]MultiSearchResponse, error) {
+	var buf bytes.Buffer
+
+	for _, r := range requests {
+		header, err := json.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+
+		body := []byte("{}")
+		if r.Body != nil {
+			body, err = json.Marshal(r.Body)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		buf.Write(header)
+		buf.WriteByte('\n')
+		buf.Write(body)
+		buf.WriteByte('\n')
+	}
+
+	path := "/_msearch"
+
+	if opts.MaxConcurrentSearches > 0 {
+		path += "?max_concurrent_searches=" + strconv.Itoa(opts.MaxConcurrentSearches)
+	}
+
+	var res struct {
+		Responses []json.RawMessage `json:"responses"`
+	}
+
+	if err := c.RequestContext(ctx, "POST", path, &buf, &res); err != nil {
+		return nil, err
+	}
+
+	if len(res.Responses) != len(requests) {
+		return nil, fmt.Errorf("elastic: expected %d responses, got %d", len(requests), len(res.Responses))
+	}
+
+	responses := make([]MultiSearchResponse, len(res.Responses))
+
+	for i, b := range res.Responses {
+		var status struct {
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		}
+
+		if err := json.Unmarshal(b, &status); err != nil {
+			return nil, err
+		}
+
+		if status.Error != nil {
+			responses[i].Err = newError(status.Status, b)
+			continue
+		}
+
+		result := new(SearchResult)
+		if err := json.Unmarshal(b, result); err != nil {
+			return nil, err
+		}
+
+		responses[i].Result = result
+	}
+
+	return responses, nil
+}
@@ -0,0 +1,70 @@
+package elastic
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var msearchResponse = `{
+  "took": 4,
+  "responses": [
+    {
+      "took": 2, "timed_out": false,
+      "_shards": { "total": 1, "successful": 1, "skipped": 0, "failed": 0 },
+      "hits": { "total": { "value": 1, "relation": "eq" }, "max_score": 1.0, "hits": [
+        { "_index": "pets", "_id": "tobi", "_score": 1.0, "_source": { "name": "Tobi", "species": "ferret" } }
+      ] },
+      "status": 200
+    },
+    {
+      "error": {
+        "root_cause": [{ "type": "index_not_found_exception", "reason": "no such index [missing]" }],
+        "type": "index_not_found_exception", "reason": "no such index [missing]"
+      },
+      "status": 404
+    }
+  ]
+}`
+
+func TestClient_MultiSearch(t *testing.T) {
+	var uri, body string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uri = r.URL.RequestURI()
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(msearchResponse))
+	}))
+	defer ts.Close()
+
+	cache := false
+
+	responses, err := New(ts.URL).MultiSearch(context.Background(), []SearchRequest{
+		{Index: "pets", Body: map[string]interface{}{"query": map[string]interface{}{"term": map[string]string{"name": "tobi"}}}, RequestCache: &cache},
+		{Index: "missing", Routing: "cats"},
+	}, MultiSearchOptions{MaxConcurrentSearches: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/_msearch?max_concurrent_searches=2", uri)
+	assert.Equal(t, `{"index":"pets","request_cache":false}
+{"query":{"term":{"name":"tobi"}}}
+{"index":"missing","routing":"cats"}
+{}
+`, body)
+
+	assert.Len(t, responses, 2)
+
+	assert.NoError(t, responses[0].Err)
+	pets, err := Hits[pet](responses[0].Result)
+	assert.NoError(t, err)
+	assert.Equal(t, []pet{{"Tobi", "ferret"}}, pets)
+
+	assert.Nil(t, responses[1].Result)
+	assert.True(t, IsNotFound(responses[1].Err))
+	assert.EqualError(t, responses[1].Err, "elastic: 404 Not Found: index_not_found_exception: no such index [missing]")
+}
@@ -0,0 +1,236 @@
+package elastic
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Dead node timeouts, doubling on each consecutive failure.
+var (
+	minDeadTimeout = time.Second
+	maxDeadTimeout = time.Minute
+)
+
+// pingTimeout is the timeout of pings resurrecting dead nodes.
+var pingTimeout = time.Second
+
+// node is an Elasticsearch node.
+type node struct {
+	url      string
+	dead     bool
+	failures int
+	retryAt  time.Time
+}
+
+// nodePool distributes requests across nodes round-robin. Nodes are marked
+// dead on connection errors and resurrected with a ping after a backoff.
+type nodePool struct {
+	sync.Mutex
+	nodes []*node
+	next  int
+}
+
+// newNodePool returns a pool of `urls`.
+func newNodePool(urls []string) *nodePool {
+	p := &nodePool{}
+	for _, u := range urls {
+		p.nodes = append(p.nodes, &node{url: u})
+	}
+	return p
+}
+
+// URLs returns the node urls.
+func (p *nodePool) URLs() (v []string) {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, n := range p.nodes {
+		v = append(v, n.url)
+	}
+
+	return
+}
+
+// Set replaces the nodes with `urls`, retaining the state of existing nodes.
+func (p *nodePool) Set(urls []string) {
+	p.Lock()
+	defer p.Unlock()
+
+	prev := make(map[string]*node)
+	for _, n := range p.nodes {
+		prev[n.url] = n
+	}
+
+	p.nodes = nil
+	for _, u := range urls {
+		n, ok := prev[u]
+		if !ok {
+			n = &node{url: u}
+		}
+		p.nodes = append(p.nodes, n)
+	}
+
+	p.next = 0
+}
+
+// Len returns the number of nodes.
+func (p *nodePool) Len() int {
+	p.Lock()
+	defer p.Unlock()
+	return len(p.nodes)
+}
+
+// Pick returns the next live node, or a dead node due for resurrection. When
+// all nodes are dead the one due soonest is returned with force set.
+func (p *nodePool) Pick(now time.Time) (n *node, dead, force bool) {
+	p.Lock()
+	defer p.Unlock()
+
+	size := len(p.nodes)
+
+	for i := 0; i < size; i++ {
+		n := p.nodes[(p.next+i)%size]
+		if !n.dead || !now.Before(n.retryAt) {
+			p.next = (p.next + i + 1) % size
+			return n, n.dead, false
+		}
+	}
+
+	for _, c := range p.nodes {
+		if n == nil || c.retryAt.Before(n.retryAt) {
+			n = c
+		}
+	}
+
+	return n, true, true
+}
+
+// MarkDead marks `n` as dead.
+func (p *nodePool) MarkDead(n *node, now time.Time) {
+	p.Lock()
+	defer p.Unlock()
+
+	d := minDeadTimeout << uint(n.failures)
+	if d > maxDeadTimeout || d <= 0 {
+		d = maxDeadTimeout
+	}
+
+	n.dead = true
+	n.failures++
+	n.retryAt = now.Add(d)
+}
+
+// MarkAlive marks `n` as alive.
+func (p *nodePool) MarkAlive(n *node) {
+	p.Lock()
+	defer p.Unlock()
+
+	n.dead = false
+	n.failures = 0
+	n.retryAt = time.Time{}
+}
+
+// Nodes returns the node urls requests are distributed across.
+func (c *Client) Nodes() []string {
+	p := c.pool()
+	if p == nil {
+		return []string{c.URL}
+	}
+
+	return p.URLs()
+}
+
+// SetNodes sets the node `urls` requests are distributed across.
+func (c *Client) SetNodes(urls ...string) {
+	c.mu.Lock()
+	c.nodes = newNodePool(urls)
+	c.mu.Unlock()
+}
+
+// setNodes replaces the node `urls`, retaining the state of existing nodes.
+func (c *Client) setNodes(urls []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.nodes == nil {
+		c.nodes = newNodePool(urls)
+		return
+	}
+
+	c.nodes.Set(urls)
+}
+
+// pool returns the node pool, or nil when the client is not configured with nodes.
+func (c *Client) pool() *nodePool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.nodes
+}
+
+// node returns the node to perform a request against, or nil when the
+// client is not configured with nodes.
+func (c *Client) node(ctx context.Context) *node {
+	p := c.pool()
+	if p == nil {
+		return nil
+	}
+
+	for i, size := 0, p.Len(); i < size; i++ {
+		n, dead, force := p.Pick(time.Now())
+
+		if !dead || force {
+			return n
+		}
+
+		if c.ping(ctx, n.url) {
+			p.MarkAlive(n)
+			return n
+		}
+
+		p.MarkDead(n, time.Now())
+	}
+
+	n, _, _ := p.Pick(time.Now())
+	return n
+}
+
+// ping returns true if the node at `url` responds within the ping timeout.
+func (c *Client) ping(ctx context.Context, url string) bool {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	req, err := c.newRequest(ctx, "HEAD", url, "/", nil, nil)
+	if err != nil {
+		return false
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return false
+	}
+	res.Body.Close()
+
+	return res.StatusCode < http.StatusInternalServerError
+}
+
+// release reports the outcome of a request to node `n`, where `err` is a
+// transport error. Only connection errors mark the node dead, as other
+// errors such as timeouts may be due to a slow request on a healthy node.
+func (c *Client) release(n *node, err error) {
+	if n == nil {
+		return
+	}
+
+	p := c.pool()
+
+	if err != nil {
+		if DefaultRetryError(err) {
+			p.MarkDead(n, time.Now())
+		}
+		return
+	}
+
+	p.MarkAlive(n)
+}
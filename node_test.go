@@ -0,0 +1,170 @@
+package elastic
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_nodes(t *testing.T) {
+	var a, b int
+
+	tsA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { a++ }))
+	defer tsA.Close()
+
+	tsB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { b++ }))
+	defer tsB.Close()
+
+	client := New(tsA.URL, tsB.URL)
+	assert.Equal(t, []string{tsA.URL, tsB.URL}, client.Nodes())
+
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, client.RefreshAll())
+	}
+
+	assert.Equal(t, 2, a)
+	assert.Equal(t, 2, b)
+}
+
+func TestClient_nodes_failover(t *testing.T) {
+	var served []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	dead.Close()
+
+	client := New(dead.URL, ts.URL)
+	client.OnRequest = func(t RequestTrace) {
+		if t.Err == nil {
+			served = append(served, t.Node)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, client.RefreshAll())
+	}
+
+	assert.Equal(t, []string{ts.URL, ts.URL, ts.URL}, served)
+}
+
+func TestClient_nodes_sentError(t *testing.T) {
+	var requests int32
+
+	// closes the connection after reading the request
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		atomic.AddInt32(&requests, 1)
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	})
+
+	tsA := httptest.NewServer(handler)
+	defer tsA.Close()
+
+	tsB := httptest.NewServer(handler)
+	defer tsB.Close()
+
+	client := New(tsA.URL, tsB.URL)
+
+	for i := 0; i < 2; i++ {
+		assert.Error(t, client.Bulk(strings.NewReader(docs)))
+		assert.Equal(t, int32(i+1), atomic.LoadInt32(&requests))
+	}
+
+	assert.Equal(t, []string{tsA.URL, tsB.URL}, client.Nodes())
+
+	for _, n := range client.pool().nodes {
+		assert.False(t, n.dead, "dead")
+	}
+}
+
+func TestClient_nodes_responseError(t *testing.T) {
+	var requests int
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte(`not gzip`))
+	})
+
+	tsA := httptest.NewServer(handler)
+	defer tsA.Close()
+
+	tsB := httptest.NewServer(handler)
+	defer tsB.Close()
+
+	client := New(tsA.URL, tsB.URL)
+	client.Retry = &RetryPolicy{MaxAttempts: 3}
+	client.Compress = true
+
+	assert.Error(t, client.RefreshAll())
+	assert.Equal(t, 1, requests)
+
+	for _, n := range client.nodes.nodes {
+		assert.False(t, n.dead, n.url)
+	}
+}
+
+func TestClient_nodes_pingTimeout(t *testing.T) {
+	pingTimeout = 50 * time.Millisecond
+	defer func() { pingTimeout = time.Second }()
+
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	blackhole := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-unblock:
+		case <-r.Context().Done():
+		}
+	}))
+	defer blackhole.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	client := New(blackhole.URL, ts.URL)
+	client.nodes.MarkDead(client.nodes.nodes[0], time.Now().Add(-time.Hour))
+
+	start := time.Now()
+	assert.NoError(t, client.RefreshAll())
+	assert.True(t, time.Since(start) < time.Second, "ping timed out")
+}
+
+func TestNodePool(t *testing.T) {
+	p := newNodePool([]string{"a", "b"})
+	now := time.Now()
+
+	n, dead, force := p.Pick(now)
+	assert.Equal(t, "a", n.url)
+	assert.False(t, dead)
+	assert.False(t, force)
+
+	p.MarkDead(n, now)
+
+	n, _, _ = p.Pick(now)
+	assert.Equal(t, "b", n.url)
+
+	n, _, _ = p.Pick(now)
+	assert.Equal(t, "b", n.url)
+
+	n, dead, force = p.Pick(now.Add(minDeadTimeout))
+	assert.Equal(t, "a", n.url)
+	assert.True(t, dead)
+	assert.False(t, force)
+
+	p.MarkDead(p.nodes[1], now)
+
+	n, dead, force = p.Pick(now)
+	assert.Equal(t, "a", n.url)
+	assert.True(t, dead)
+	assert.True(t, force)
+}
@@ -0,0 +1,250 @@
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrInvalidCursor is returned when resuming from a malformed cursor.
+var ErrInvalidCursor = errors.New("elastic: invalid cursor")
+
+// PaginateOptions configures pagination.
+type PaginateOptions struct {
+	Size       int           // Page size, defaults to 10
+	Sort       []interface{} // Sort such as []interface{}{map[string]string{"born": "desc"}}
+	KeepAlive  time.Duration // Point-in-time keep alive between pages, defaults to 1m
+	Tiebreaker string        // Unique field sorted on last without point-in-time support, defaults to "_id"
+	NoPIT      bool          // Disable point-in-time, paging with search_after alone
+	Cursor     string        // Cursor to resume from, see Paginator.Cursor
+}
+
+// cursor is the state of a Paginator.
+type cursor struct {
+	PIT   string            `json:"p,omitempty"`
+	After []json.RawMessage `json:"a,omitempty"`
+	Done  bool              `json:"d,omitempty"`
+}
+
+// Paginator pages through results with search_after, consistently across
+// pages against a point-in-time when the cluster supports it (7.10+).
+type Paginator struct {
+	client *Client
+	index  string
+	query  interface{}
+	opts   PaginateOptions
+	cursor cursor
+	opened bool
+}
+
+// Paginate returns a paginator over the results of `query` against `index`,
+// where `query` is a query clause such as those in the query package, or nil
+// to match all documents.
+func (c *Client) Paginate(index string, query interface{}, opts PaginateOptions) (*Paginator, error) {
+	if opts.Size == 0 {
+		opts.Size = 10
+	}
+
+	if opts.KeepAlive == 0 {
+		opts.KeepAlive = time.Minute
+	}
+
+	if opts.Tiebreaker == "" {
+		opts.Tiebreaker = "_id"
+	}
+
+	p := &Paginator{
+		client: c,
+		index:  index,
+		query:  query,
+		opts:   opts,
+	}
+
+	if opts.Cursor != "" {
+		b, err := base64.RawURLEncoding.DecodeString(opts.Cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+
+		if err := json.Unmarshal(b, &p.cursor); err != nil {
+			return nil, ErrInvalidCursor
+		}
+
+		p.opened = true
+	}
+
+	return p, nil
+}
+
+// Next returns the next page, or io.EOF when the results are exhausted. The
+// point-in-time is closed after the last page, or by Close if that fails.
+func (p *Paginator) Next(ctx context.Context) (*SearchResult, error) {
+	if p.cursor.Done {
+		return nil, io.EOF
+	}
+
+	if !p.opened {
+		if err := p.open(ctx); err != nil {
+			return nil, err
+		}
+		p.opened = true
+	}
+
+	body := map[string]interface{}{
+		"size": p.opts.Size,
+		"sort": p.sort(),
+	}
+
+	if p.query != nil {
+		body["query"] = p.query
+	}
+
+	if p.cursor.After != nil {
+		body["search_after"] = p.cursor.After
+	}
+
+	path := fmt.Sprintf("/%s/_search", p.index)
+
+	if p.cursor.PIT != "" {
+		path = "/_search"
+		body["pit"] = map[string]string{
+			"id":         p.cursor.PIT,
+			"keep_alive": keepAlive(p.opts.KeepAlive),
+		}
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(SearchResult)
+	if err := p.client.RequestContext(ctx, "POST", path, bytes.NewReader(b), res); err != nil {
+		return nil, err
+	}
+
+	if res.PITID != "" {
+		p.cursor.PIT = res.PITID
+	}
+
+	hits := res.Hits.Hits
+
+	if len(hits) > 0 {
+		p.cursor.After = hits[len(hits)-1].Sort
+	}
+
+	// failing to close the point-in-time is not fatal, Close retries it
+	if len(hits) < p.opts.Size {
+		p.cursor.Done = true
+		p.Close(ctx)
+	}
+
+	if len(hits) == 0 {
+		return nil, io.EOF
+	}
+
+	return res, nil
+}
+
+// Cursor returns an opaque cursor which may be passed as PaginateOptions.Cursor
+// to resume after the current page, for example from another request. Note that
+// the point-in-time expires unless resumed within the keep alive.
+func (p *Paginator) Cursor() string {
+	b, _ := json.Marshal(p.cursor)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Done returns true when the results are exhausted.
+func (p *Paginator) Done() bool {
+	return p.cursor.Done
+}
+
+// Close closes the point-in-time, if any.
+func (p *Paginator) Close(ctx context.Context) error {
+	if p.cursor.PIT == "" {
+		return nil
+	}
+
+	b, _ := json.Marshal(map[string]string{
+		"id": p.cursor.PIT,
+	})
+
+	err := p.client.RequestContext(ctx, "DELETE", "/_pit", bytes.NewReader(b), nil)
+	if err != nil && !IsNotFound(err) {
+		return err
+	}
+
+	p.cursor.PIT = ""
+	return nil
+}
+
+// open opens a point-in-time, falling back to plain search_after
+// when the cluster does not support it.
+func (p *Paginator) open(ctx context.Context) error {
+	if p.opts.NoPIT {
+		return nil
+	}
+
+	var res struct {
+		ID string `json:"id"`
+	}
+
+	path := fmt.Sprintf("/%s/_pit?keep_alive=%s", p.index, keepAlive(p.opts.KeepAlive))
+	err := p.client.RequestContext(ctx, "POST", path, nil, &res)
+
+	if unsupported(err) {
+		p.opts.NoPIT = true
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	p.cursor.PIT = res.ID
+	return nil
+}
+
+// sort returns the sort, with a tiebreaker without a point-in-time.
+func (p *Paginator) sort() []interface{} {
+	sort := append([]interface{}{}, p.opts.Sort...)
+
+	if p.cursor.PIT == "" {
+		return append(sort, map[string]string{p.opts.Tiebreaker: "asc"})
+	}
+
+	// point-in-time searches have an implicit _shard_doc tiebreaker
+	if len(sort) == 0 {
+		sort = append(sort, "_shard_doc")
+	}
+
+	return sort
+}
+
+// unsupported returns true if `err` indicates an unsupported endpoint.
+func unsupported(err error) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+
+	switch e.Status {
+	case http.StatusBadRequest, http.StatusMethodNotAllowed:
+		return true
+	case http.StatusNotFound:
+		return e.Type != "index_not_found_exception"
+	default:
+		return false
+	}
+}
+
+// keepAlive returns duration `d` in the Elasticsearch time unit format.
+func keepAlive(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
@@ -0,0 +1,204 @@
+package elastic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// paginateServer returns a server paging `total` documents, with
+// point-in-time support when `pit` is true.
+func paginateServer(t *testing.T, total int, pit bool, closed *bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/pets/_pit":
+			if !pit {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":{"type":"illegal_argument_exception","reason":"no handler"}}`)
+				return
+			}
+			assert.Equal(t, "60000ms", r.URL.Query().Get("keep_alive"))
+			fmt.Fprint(w, `{"id":"pit-1"}`)
+			return
+		case r.Method == "DELETE" && r.URL.Path == "/_pit":
+			*closed = true
+			return
+		}
+
+		b, _ := ioutil.ReadAll(r.Body)
+
+		var body struct {
+			Size        int           `json:"size"`
+			Sort        []interface{} `json:"sort"`
+			SearchAfter []float64     `json:"search_after"`
+			PIT         struct {
+				ID string `json:"id"`
+			} `json:"pit"`
+		}
+
+		assert.NoError(t, json.Unmarshal(b, &body))
+
+		if pit {
+			assert.Equal(t, "/_search", r.URL.Path)
+			assert.Equal(t, "pit-1", body.PIT.ID)
+			assert.Equal(t, []interface{}{"_shard_doc"}, body.Sort)
+		} else {
+			assert.Equal(t, "/pets/_search", r.URL.Path)
+			assert.Equal(t, []interface{}{map[string]interface{}{"_id": "asc"}}, body.Sort)
+		}
+
+		from := 0
+		if len(body.SearchAfter) > 0 {
+			from = int(body.SearchAfter[0]) + 1
+		}
+
+		var hits []*SearchHit
+		for i := from; i < total && i < from+body.Size; i++ {
+			hits = append(hits, &SearchHit{ID: fmt.Sprint(i), Sort: []json.RawMessage{json.RawMessage(fmt.Sprint(i))}})
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"pit_id": body.PIT.ID,
+			"hits":   map[string]interface{}{"hits": hits},
+		})
+	}))
+}
+
+// ids returns the ids of all pages.
+func ids(t *testing.T, p *Paginator) (v []string) {
+	for {
+		res, err := p.Next(context.Background())
+		if err == io.EOF {
+			return
+		}
+
+		assert.NoError(t, err)
+
+		for _, hit := range res.Hits.Hits {
+			v = append(v, hit.ID)
+		}
+	}
+}
+
+func TestClient_Paginate(t *testing.T) {
+	var closed bool
+	ts := paginateServer(t, 5, true, &closed)
+	defer ts.Close()
+
+	p, err := New(ts.URL).Paginate("pets", nil, PaginateOptions{Size: 2})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"0", "1", "2", "3", "4"}, ids(t, p))
+	assert.True(t, p.Done(), "done")
+	assert.True(t, closed, "closed")
+}
+
+func TestClient_Paginate_closeError(t *testing.T) {
+	var closed bool
+	backend := paginateServer(t, 5, true, &closed)
+	defer backend.Close()
+
+	fail := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" && fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		backend.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	p, err := New(ts.URL).Paginate("pets", nil, PaginateOptions{Size: 2})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"0", "1", "2", "3", "4"}, ids(t, p))
+	assert.True(t, p.Done(), "done")
+	assert.False(t, closed, "closed")
+
+	assert.True(t, IsStatus(p.Close(context.Background()), http.StatusInternalServerError))
+
+	fail = false
+	assert.NoError(t, p.Close(context.Background()))
+	assert.True(t, closed, "closed")
+}
+
+func TestClient_Paginate_cursor(t *testing.T) {
+	var closed bool
+	ts := paginateServer(t, 5, true, &closed)
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	p, err := client.Paginate("pets", nil, PaginateOptions{Size: 2})
+	assert.NoError(t, err)
+
+	_, err = p.Next(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, closed, "closed")
+
+	p, err = client.Paginate("pets", nil, PaginateOptions{Size: 2, Cursor: p.Cursor()})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2", "3", "4"}, ids(t, p))
+
+	_, err = client.Paginate("pets", nil, PaginateOptions{Cursor: "!"})
+	assert.Equal(t, ErrInvalidCursor, err)
+}
+
+func TestClient_Paginate_noPIT(t *testing.T) {
+	var closed bool
+	ts := paginateServer(t, 4, false, &closed)
+	defer ts.Close()
+
+	p, err := New(ts.URL).Paginate("pets", nil, PaginateOptions{Size: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0", "1", "2", "3"}, ids(t, p))
+	assert.False(t, closed, "closed")
+}
+
+func TestClient_Paginate_longSort(t *testing.T) {
+	var searchAfter []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+
+		var body struct {
+			SearchAfter []json.RawMessage `json:"search_after"`
+		}
+
+		assert.NoError(t, json.Unmarshal(b, &body))
+
+		if body.SearchAfter == nil {
+			fmt.Fprint(w, `{ "hits": { "hits": [{ "_id": "1", "sort": [1700000000123456789, "1"] }] } }`)
+			return
+		}
+
+		for _, v := range body.SearchAfter {
+			searchAfter = append(searchAfter, string(v))
+		}
+
+		fmt.Fprint(w, `{ "hits": { "hits": [] } }`)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	p, err := client.Paginate("pets", nil, PaginateOptions{Size: 1, NoPIT: true})
+	assert.NoError(t, err)
+
+	_, err = p.Next(context.Background())
+	assert.NoError(t, err)
+
+	p, err = client.Paginate("pets", nil, PaginateOptions{Size: 1, NoPIT: true, Cursor: p.Cursor()})
+	assert.NoError(t, err)
+
+	_, err = p.Next(context.Background())
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, []string{"1700000000123456789", `"1"`}, searchAfter)
+}
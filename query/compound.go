@@ -0,0 +1,223 @@
+package query
+
+import (
+	"encoding/json"
+)
+
+// BoolQuery combines queries with boolean clauses.
+type BoolQuery struct {
+	must    []Query
+	should  []Query
+	filter  []Query
+	mustNot []Query
+	params  params
+}
+
+// Bool returns a boolean query.
+func Bool() *BoolQuery {
+	return &BoolQuery{params: params{}}
+}
+
+// Must adds queries which must match and contribute to the score.
+func (q *BoolQuery) Must(v ...Query) *BoolQuery {
+	q.must = append(q.must, v...)
+	return q
+}
+
+// Should adds queries which should match.
+func (q *BoolQuery) Should(v ...Query) *BoolQuery {
+	q.should = append(q.should, v...)
+	return q
+}
+
+// Filter adds queries which must match and do not contribute to the score.
+func (q *BoolQuery) Filter(v ...Query) *BoolQuery {
+	q.filter = append(q.filter, v...)
+	return q
+}
+
+// MustNot adds queries which must not match.
+func (q *BoolQuery) MustNot(v ...Query) *BoolQuery {
+	q.mustNot = append(q.mustNot, v...)
+	return q
+}
+
+// MinimumShouldMatch sets the number of should clauses which must match, such as "1" or "75%".
+func (q *BoolQuery) MinimumShouldMatch(v string) *BoolQuery {
+	q.params["minimum_should_match"] = v
+	return q
+}
+
+// Boost sets the boost.
+func (q *BoolQuery) Boost(v float64) *BoolQuery {
+	q.params["boost"] = v
+	return q
+}
+
+// MarshalJSON implementation.
+func (q *BoolQuery) MarshalJSON() ([]byte, error) {
+	p := params{}
+
+	for k, v := range q.params {
+		p[k] = v
+	}
+
+	if len(q.must) > 0 {
+		p["must"] = q.must
+	}
+
+	if len(q.should) > 0 {
+		p["should"] = q.should
+	}
+
+	if len(q.filter) > 0 {
+		p["filter"] = q.filter
+	}
+
+	if len(q.mustNot) > 0 {
+		p["must_not"] = q.mustNot
+	}
+
+	return json.Marshal(params{"bool": p})
+}
+
+// NestedQuery queries nested objects.
+type NestedQuery struct{ objectQuery }
+
+// Nested returns a query matching documents with nested objects at `path` matching `query`.
+func Nested(path string, query Query) *NestedQuery {
+	return &NestedQuery{objectQuery{"nested", params{
+		"path":  path,
+		"query": query,
+	}}}
+}
+
+// ScoreMode sets how nested scores are combined, such as "avg" or "max".
+func (q *NestedQuery) ScoreMode(v string) *NestedQuery {
+	q.params["score_mode"] = v
+	return q
+}
+
+// IgnoreUnmapped enables ignoring an unmapped path.
+func (q *NestedQuery) IgnoreUnmapped(v bool) *NestedQuery {
+	q.params["ignore_unmapped"] = v
+	return q
+}
+
+// Function is a function_score function.
+type Function map[string]interface{}
+
+// Weight returns a function multiplying the score by `v`.
+func Weight(v float64) Function {
+	return Function{"weight": v}
+}
+
+// FieldValueFactor returns a function scoring by the value of `field`.
+func FieldValueFactor(field string, factor float64, modifier string) Function {
+	p := params{"field": field, "factor": factor}
+
+	if modifier != "" {
+		p["modifier"] = modifier
+	}
+
+	return Function{"field_value_factor": p}
+}
+
+// RandomScore returns a function scoring randomly, reproducibly
+// for the given `seed` and `field` when non-empty.
+func RandomScore(seed interface{}, field string) Function {
+	p := params{}
+
+	if seed != nil {
+		p["seed"] = seed
+	}
+
+	if field != "" {
+		p["field"] = field
+	}
+
+	return Function{"random_score": p}
+}
+
+// Decay returns a decay function of `kind` ("gauss", "linear" or "exp")
+// scoring by the distance of `field` from `origin` relative to `scale`.
+func Decay(kind, field string, origin, scale interface{}) Function {
+	return Function{kind: params{
+		field: params{
+			"origin": origin,
+			"scale":  scale,
+		},
+	}}
+}
+
+// ScriptScore returns a function scoring with the script `source`.
+func ScriptScore(source string, scriptParams map[string]interface{}) Function {
+	script := params{"source": source}
+
+	if scriptParams != nil {
+		script["params"] = scriptParams
+	}
+
+	return Function{"script_score": params{"script": script}}
+}
+
+// Filter returns a copy of the function applied only to documents matching `q`.
+func (f Function) Filter(q Query) Function {
+	return f.with("filter", q)
+}
+
+// Weight returns a copy of the function with weight `v`.
+func (f Function) Weight(v float64) Function {
+	return f.with("weight", v)
+}
+
+// with returns a copy of the function with `key` set to `value`.
+func (f Function) with(key string, value interface{}) Function {
+	c := make(Function, len(f)+1)
+	for k, v := range f {
+		c[k] = v
+	}
+	c[key] = value
+	return c
+}
+
+// FunctionScoreQuery modifies the scores of a query.
+type FunctionScoreQuery struct{ objectQuery }
+
+// FunctionScore returns a query modifying the scores of documents matching `query`.
+func FunctionScore(query Query) *FunctionScoreQuery {
+	return &FunctionScoreQuery{objectQuery{"function_score", params{
+		"query":     query,
+		"functions": []Function{},
+	}}}
+}
+
+// Functions adds score functions.
+func (q *FunctionScoreQuery) Functions(v ...Function) *FunctionScoreQuery {
+	q.params["functions"] = append(q.params["functions"].([]Function), v...)
+	return q
+}
+
+// ScoreMode sets how function scores are combined, such as "multiply" or "sum".
+func (q *FunctionScoreQuery) ScoreMode(v string) *FunctionScoreQuery {
+	q.params["score_mode"] = v
+	return q
+}
+
+// BoostMode sets how the function score is combined with the query score.
+func (q *FunctionScoreQuery) BoostMode(v string) *FunctionScoreQuery {
+	q.params["boost_mode"] = v
+	return q
+}
+
+// MaxBoost sets the maximum function score.
+func (q *FunctionScoreQuery) MaxBoost(v float64) *FunctionScoreQuery {
+	q.params["max_boost"] = v
+	return q
+}
+
+// MinScore sets the minimum score of matching documents.
+func (q *FunctionScoreQuery) MinScore(v float64) *FunctionScoreQuery {
+	q.params["min_score"] = v
+	return q
+}
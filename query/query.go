@@ -0,0 +1,375 @@
+// Package query provides builders for the Elasticsearch query DSL. Queries
+// marshal to their JSON representation, and may be wrapped with Search to
+// form a request body for Client.SearchIndex.
+package query
+
+import (
+	"encoding/json"
+)
+
+// Query is a query clause.
+type Query interface {
+	json.Marshaler
+}
+
+// params of a query.
+type params map[string]interface{}
+
+// fieldQuery is a query of the form {kind: {field: params}}.
+type fieldQuery struct {
+	kind   string
+	field  string
+	params params
+}
+
+// newFieldQuery returns a field query of `kind` with param `key` set to `value`.
+func newFieldQuery(kind, field, key string, value interface{}) fieldQuery {
+	return fieldQuery{
+		kind:   kind,
+		field:  field,
+		params: params{key: value},
+	}
+}
+
+// MarshalJSON implementation.
+func (q fieldQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(params{
+		q.kind: params{q.field: q.params},
+	})
+}
+
+// objectQuery is a query of the form {kind: params}.
+type objectQuery struct {
+	kind   string
+	params params
+}
+
+// MarshalJSON implementation.
+func (q objectQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(params{q.kind: q.params})
+}
+
+// MatchAllQuery matches all documents.
+type MatchAllQuery struct{ objectQuery }
+
+// MatchAll returns a query matching all documents.
+func MatchAll() *MatchAllQuery {
+	return &MatchAllQuery{objectQuery{"match_all", params{}}}
+}
+
+// Boost sets the boost.
+func (q *MatchAllQuery) Boost(v float64) *MatchAllQuery {
+	q.params["boost"] = v
+	return q
+}
+
+// TermQuery matches an exact term.
+type TermQuery struct{ fieldQuery }
+
+// Term returns a query matching documents where `field` is exactly `value`.
+func Term(field string, value interface{}) *TermQuery {
+	return &TermQuery{newFieldQuery("term", field, "value", value)}
+}
+
+// Boost sets the boost.
+func (q *TermQuery) Boost(v float64) *TermQuery {
+	q.params["boost"] = v
+	return q
+}
+
+// TermsQuery matches any of several exact terms.
+type TermsQuery struct {
+	field  string
+	values []interface{}
+	boost  *float64
+}
+
+// Terms returns a query matching documents where `field` is exactly one of `values`.
+func Terms(field string, values ...interface{}) *TermsQuery {
+	if values == nil {
+		values = []interface{}{}
+	}
+
+	return &TermsQuery{field: field, values: values}
+}
+
+// Boost sets the boost.
+func (q *TermsQuery) Boost(v float64) *TermsQuery {
+	q.boost = &v
+	return q
+}
+
+// MarshalJSON implementation.
+func (q *TermsQuery) MarshalJSON() ([]byte, error) {
+	p := params{q.field: q.values}
+
+	if q.boost != nil {
+		p["boost"] = *q.boost
+	}
+
+	return json.Marshal(params{"terms": p})
+}
+
+// MatchQuery is a full text match.
+type MatchQuery struct{ fieldQuery }
+
+// Match returns a full text query of `field` for `text`.
+func Match(field string, text interface{}) *MatchQuery {
+	return &MatchQuery{newFieldQuery("match", field, "query", text)}
+}
+
+// Operator sets the boolean operator, "or" or "and".
+func (q *MatchQuery) Operator(v string) *MatchQuery {
+	q.params["operator"] = v
+	return q
+}
+
+// Fuzziness sets the fuzziness such as "AUTO".
+func (q *MatchQuery) Fuzziness(v string) *MatchQuery {
+	q.params["fuzziness"] = v
+	return q
+}
+
+// Analyzer sets the analyzer.
+func (q *MatchQuery) Analyzer(v string) *MatchQuery {
+	q.params["analyzer"] = v
+	return q
+}
+
+// MinimumShouldMatch sets the minimum number of clauses which must match, such as "75%".
+func (q *MatchQuery) MinimumShouldMatch(v string) *MatchQuery {
+	q.params["minimum_should_match"] = v
+	return q
+}
+
+// Boost sets the boost.
+func (q *MatchQuery) Boost(v float64) *MatchQuery {
+	q.params["boost"] = v
+	return q
+}
+
+// MatchPhraseQuery is a phrase match.
+type MatchPhraseQuery struct{ fieldQuery }
+
+// MatchPhrase returns a phrase query of `field` for `text`.
+func MatchPhrase(field, text string) *MatchPhraseQuery {
+	return &MatchPhraseQuery{newFieldQuery("match_phrase", field, "query", text)}
+}
+
+// Slop sets the number of positions terms may be apart.
+func (q *MatchPhraseQuery) Slop(v int) *MatchPhraseQuery {
+	q.params["slop"] = v
+	return q
+}
+
+// Analyzer sets the analyzer.
+func (q *MatchPhraseQuery) Analyzer(v string) *MatchPhraseQuery {
+	q.params["analyzer"] = v
+	return q
+}
+
+// Boost sets the boost.
+func (q *MatchPhraseQuery) Boost(v float64) *MatchPhraseQuery {
+	q.params["boost"] = v
+	return q
+}
+
+// MultiMatchQuery is a full text match across several fields.
+type MultiMatchQuery struct{ objectQuery }
+
+// MultiMatch returns a full text query of `fields` for `text`. Fields
+// may be boosted with the caret syntax such as "title^2".
+func MultiMatch(text string, fields ...string) *MultiMatchQuery {
+	return &MultiMatchQuery{objectQuery{"multi_match", params{
+		"query":  text,
+		"fields": fields,
+	}}}
+}
+
+// Type sets the type such as "best_fields", "most_fields" or "phrase".
+func (q *MultiMatchQuery) Type(v string) *MultiMatchQuery {
+	q.params["type"] = v
+	return q
+}
+
+// Operator sets the boolean operator, "or" or "and".
+func (q *MultiMatchQuery) Operator(v string) *MultiMatchQuery {
+	q.params["operator"] = v
+	return q
+}
+
+// Fuzziness sets the fuzziness such as "AUTO".
+func (q *MultiMatchQuery) Fuzziness(v string) *MultiMatchQuery {
+	q.params["fuzziness"] = v
+	return q
+}
+
+// TieBreaker sets the tie breaker.
+func (q *MultiMatchQuery) TieBreaker(v float64) *MultiMatchQuery {
+	q.params["tie_breaker"] = v
+	return q
+}
+
+// Boost sets the boost.
+func (q *MultiMatchQuery) Boost(v float64) *MultiMatchQuery {
+	q.params["boost"] = v
+	return q
+}
+
+// RangeQuery matches a range of values.
+type RangeQuery struct{ fieldQuery }
+
+// Range returns a query matching a range of `field` values.
+func Range(field string) *RangeQuery {
+	return &RangeQuery{fieldQuery{"range", field, params{}}}
+}
+
+// Gt sets the exclusive lower bound.
+func (q *RangeQuery) Gt(v interface{}) *RangeQuery {
+	q.params["gt"] = v
+	return q
+}
+
+// Gte sets the inclusive lower bound.
+func (q *RangeQuery) Gte(v interface{}) *RangeQuery {
+	q.params["gte"] = v
+	return q
+}
+
+// Lt sets the exclusive upper bound.
+func (q *RangeQuery) Lt(v interface{}) *RangeQuery {
+	q.params["lt"] = v
+	return q
+}
+
+// Lte sets the inclusive upper bound.
+func (q *RangeQuery) Lte(v interface{}) *RangeQuery {
+	q.params["lte"] = v
+	return q
+}
+
+// Format sets the date format.
+func (q *RangeQuery) Format(v string) *RangeQuery {
+	q.params["format"] = v
+	return q
+}
+
+// TimeZone sets the time zone of dates such as "+01:00".
+func (q *RangeQuery) TimeZone(v string) *RangeQuery {
+	q.params["time_zone"] = v
+	return q
+}
+
+// Boost sets the boost.
+func (q *RangeQuery) Boost(v float64) *RangeQuery {
+	q.params["boost"] = v
+	return q
+}
+
+// ExistsQuery matches documents with a field.
+type ExistsQuery struct{ objectQuery }
+
+// Exists returns a query matching documents with a value for `field`.
+func Exists(field string) *ExistsQuery {
+	return &ExistsQuery{objectQuery{"exists", params{"field": field}}}
+}
+
+// PrefixQuery matches a term prefix.
+type PrefixQuery struct{ fieldQuery }
+
+// Prefix returns a query matching documents where `field` starts with `value`.
+func Prefix(field, value string) *PrefixQuery {
+	return &PrefixQuery{newFieldQuery("prefix", field, "value", value)}
+}
+
+// Boost sets the boost.
+func (q *PrefixQuery) Boost(v float64) *PrefixQuery {
+	q.params["boost"] = v
+	return q
+}
+
+// WildcardQuery matches a term wildcard pattern.
+type WildcardQuery struct{ fieldQuery }
+
+// Wildcard returns a query matching documents where `field` matches
+// the wildcard `pattern` such as "ki*y".
+func Wildcard(field, pattern string) *WildcardQuery {
+	return &WildcardQuery{newFieldQuery("wildcard", field, "value", pattern)}
+}
+
+// Boost sets the boost.
+func (q *WildcardQuery) Boost(v float64) *WildcardQuery {
+	q.params["boost"] = v
+	return q
+}
+
+// RegexpQuery matches a term regular expression.
+type RegexpQuery struct{ fieldQuery }
+
+// Regexp returns a query matching documents where `field` matches
+// the regular expression `pattern`.
+func Regexp(field, pattern string) *RegexpQuery {
+	return &RegexpQuery{newFieldQuery("regexp", field, "value", pattern)}
+}
+
+// Flags sets the regular expression flags such as "ALL".
+func (q *RegexpQuery) Flags(v string) *RegexpQuery {
+	q.params["flags"] = v
+	return q
+}
+
+// Boost sets the boost.
+func (q *RegexpQuery) Boost(v float64) *RegexpQuery {
+	q.params["boost"] = v
+	return q
+}
+
+// IDsQuery matches document ids.
+type IDsQuery struct{ objectQuery }
+
+// IDs returns a query matching documents with the given `ids`.
+func IDs(ids ...string) *IDsQuery {
+	if ids == nil {
+		ids = []string{}
+	}
+
+	return &IDsQuery{objectQuery{"ids", params{"values": ids}}}
+}
+
+// QueryStringQuery is a Lucene query string.
+type QueryStringQuery struct{ objectQuery }
+
+// QueryString returns a query parsing the Lucene query string `s`.
+func QueryString(s string) *QueryStringQuery {
+	return &QueryStringQuery{objectQuery{"query_string", params{"query": s}}}
+}
+
+// DefaultField sets the default field.
+func (q *QueryStringQuery) DefaultField(v string) *QueryStringQuery {
+	q.params["default_field"] = v
+	return q
+}
+
+// Fields sets the fields queried.
+func (q *QueryStringQuery) Fields(v ...string) *QueryStringQuery {
+	q.params["fields"] = v
+	return q
+}
+
+// DefaultOperator sets the default boolean operator, "OR" or "AND".
+func (q *QueryStringQuery) DefaultOperator(v string) *QueryStringQuery {
+	q.params["default_operator"] = v
+	return q
+}
+
+// AnalyzeWildcard enables analysis of wildcard terms.
+func (q *QueryStringQuery) AnalyzeWildcard(v bool) *QueryStringQuery {
+	q.params["analyze_wildcard"] = v
+	return q
+}
+
+// Boost sets the boost.
+func (q *QueryStringQuery) Boost(v float64) *QueryStringQuery {
+	q.params["boost"] = v
+	return q
+}
@@ -0,0 +1,108 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertJSON(t *testing.T, expected string, v interface{}) {
+	b, err := json.Marshal(v)
+	assert.NoError(t, err, "marshaling")
+	assert.JSONEq(t, expected, string(b))
+}
+
+func TestLeafQueries(t *testing.T) {
+	assertJSON(t, `{"match_all":{}}`, MatchAll())
+	assertJSON(t, `{"term":{"species":{"value":"ferret","boost":2}}}`, Term("species", "ferret").Boost(2))
+	assertJSON(t, `{"terms":{"species":["ferret","cat"]}}`, Terms("species", "ferret", "cat"))
+	assertJSON(t, `{"match":{"name":{"query":"tobi loki","operator":"and"}}}`, Match("name", "tobi loki").Operator("and"))
+	assertJSON(t, `{"match_phrase":{"bio":{"query":"likes naps","slop":1}}}`, MatchPhrase("bio", "likes naps").Slop(1))
+	assertJSON(t, `{"multi_match":{"query":"tobi","fields":["name^2","bio"],"type":"best_fields"}}`, MultiMatch("tobi", "name^2", "bio").Type("best_fields"))
+	assertJSON(t, `{"range":{"age":{"gte":1,"lt":5}}}`, Range("age").Gte(1).Lt(5))
+	assertJSON(t, `{"exists":{"field":"owner"}}`, Exists("owner"))
+	assertJSON(t, `{"prefix":{"name":{"value":"to"}}}`, Prefix("name", "to"))
+	assertJSON(t, `{"wildcard":{"name":{"value":"t*i"}}}`, Wildcard("name", "t*i"))
+	assertJSON(t, `{"regexp":{"name":{"value":"t.*i","flags":"ALL"}}}`, Regexp("name", "t.*i").Flags("ALL"))
+	assertJSON(t, `{"ids":{"values":["1","2"]}}`, IDs("1", "2"))
+	assertJSON(t, `{"query_string":{"query":"name:tobi","default_operator":"AND"}}`, QueryString("name:tobi").DefaultOperator("AND"))
+}
+
+func TestBool(t *testing.T) {
+	q := Bool().
+		Must(Match("name", "tobi")).
+		Filter(Term("species", "ferret"), Range("age").Gte(2)).
+		Should(Exists("owner")).
+		MustNot(Term("adopted", true)).
+		MinimumShouldMatch("1")
+
+	assertJSON(t, `{
+    "bool": {
+      "must": [{ "match": { "name": { "query": "tobi" } } }],
+      "filter": [
+        { "term": { "species": { "value": "ferret" } } },
+        { "range": { "age": { "gte": 2 } } }
+      ],
+      "should": [{ "exists": { "field": "owner" } }],
+      "must_not": [{ "term": { "adopted": { "value": true } } }],
+      "minimum_should_match": "1"
+    }
+  }`, q)
+}
+
+func TestNested(t *testing.T) {
+	q := Nested("toys", Term("toys.kind", "ball")).ScoreMode("max")
+	assertJSON(t, `{"nested":{"path":"toys","query":{"term":{"toys.kind":{"value":"ball"}}},"score_mode":"max"}}`, q)
+}
+
+func TestFunctionScore(t *testing.T) {
+	q := FunctionScore(Match("name", "tobi")).
+		Functions(
+			FieldValueFactor("likes", 1.2, "log1p"),
+			Weight(2).Filter(Term("species", "ferret")),
+			Decay("gauss", "born", "now", "10d"),
+		).
+		ScoreMode("sum").
+		BoostMode("multiply")
+
+	assertJSON(t, `{
+    "function_score": {
+      "query": { "match": { "name": { "query": "tobi" } } },
+      "functions": [
+        { "field_value_factor": { "field": "likes", "factor": 1.2, "modifier": "log1p" } },
+        { "weight": 2, "filter": { "term": { "species": { "value": "ferret" } } } },
+        { "gauss": { "born": { "origin": "now", "scale": "10d" } } }
+      ],
+      "score_mode": "sum",
+      "boost_mode": "multiply"
+    }
+  }`, q)
+}
+
+func TestSearch(t *testing.T) {
+	s := Search(Term("species", "ferret")).
+		From(10).
+		Size(5).
+		Sort("name", "asc").
+		SortBy("_score").
+		Source("name")
+
+	assertJSON(t, `{
+    "query": { "term": { "species": { "value": "ferret" } } },
+    "from": 10,
+    "size": 5,
+    "sort": [{ "name": { "order": "asc" } }, "_score"],
+    "_source": ["name"]
+  }`, s)
+
+	assertJSON(t, `{}`, Search(nil))
+
+	s = Search(nil).
+		NoHits().
+		TrackTotalHits(10000).
+		TerminateAfter(100)
+
+	assertJSON(t, `{ "size": 0, "track_total_hits": 10000, "terminate_after": 100 }`, s)
+	assertJSON(t, `{ "track_total_hits": true }`, Search(nil).TrackTotalHits(true))
+}
@@ -0,0 +1,122 @@
+package query
+
+import (
+	"encoding/json"
+)
+
+// SearchSource is a search request body.
+type SearchSource struct {
+	query  Query
+	sort   []interface{}
+	aggs   map[string]json.Marshaler
+	params params
+}
+
+// Search returns a search request body for `query`, which
+// may be nil to match all documents.
+func Search(query Query) *SearchSource {
+	return &SearchSource{
+		query:  query,
+		params: params{},
+	}
+}
+
+// From sets the offset of the first hit.
+func (s *SearchSource) From(v int) *SearchSource {
+	s.params["from"] = v
+	return s
+}
+
+// Size sets the number of hits.
+func (s *SearchSource) Size(v int) *SearchSource {
+	s.params["size"] = v
+	return s
+}
+
+// NoHits sets the number of hits to zero, for requests
+// such as those only interested in aggregations.
+func (s *SearchSource) NoHits() *SearchSource {
+	return s.Size(0)
+}
+
+// TrackTotalHits sets whether the total hits are tracked accurately,
+// true, false, or an int to track them accurately up to that many.
+func (s *SearchSource) TrackTotalHits(v interface{}) *SearchSource {
+	s.params["track_total_hits"] = v
+	return s
+}
+
+// TerminateAfter sets the maximum number of documents collected per shard.
+func (s *SearchSource) TerminateAfter(v int) *SearchSource {
+	s.params["terminate_after"] = v
+	return s
+}
+
+// Sort adds a sort on `field` with `order`, "asc" or "desc".
+func (s *SearchSource) Sort(field, order string) *SearchSource {
+	s.sort = append(s.sort, params{field: params{"order": order}})
+	return s
+}
+
+// SortBy adds a sort such as "_score" or a sort object.
+func (s *SearchSource) SortBy(v interface{}) *SearchSource {
+	s.sort = append(s.sort, v)
+	return s
+}
+
+// Source sets the source fields returned in hits.
+func (s *SearchSource) Source(fields ...string) *SearchSource {
+	s.params["_source"] = fields
+	return s
+}
+
+// NoSource disables returning sources in hits.
+func (s *SearchSource) NoSource() *SearchSource {
+	s.params["_source"] = false
+	return s
+}
+
+// MinScore sets the minimum score of hits.
+func (s *SearchSource) MinScore(v float64) *SearchSource {
+	s.params["min_score"] = v
+	return s
+}
+
+// Aggregation adds aggregation `v` as `name`, see the aggs package.
+func (s *SearchSource) Aggregation(name string, v json.Marshaler) *SearchSource {
+	if s.aggs == nil {
+		s.aggs = make(map[string]json.Marshaler)
+	}
+	s.aggs[name] = v
+	return s
+}
+
+// Set sets `key` of the request body to `value`, for options
+// not otherwise supported.
+func (s *SearchSource) Set(key string, value interface{}) *SearchSource {
+	s.params[key] = value
+	return s
+}
+
+// MarshalJSON implementation.
+func (s *SearchSource) MarshalJSON() ([]byte, error) {
+	p := params{}
+
+	for k, v := range s.params {
+		p[k] = v
+	}
+
+	if s.query != nil {
+		p["query"] = s.query
+	}
+
+	if len(s.sort) > 0 {
+		p["sort"] = s.sort
+	}
+
+	if len(s.aggs) > 0 {
+		p["aggs"] = s.aggs
+	}
+
+	return json.Marshal(p)
+}
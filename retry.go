@@ -0,0 +1,167 @@
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryStatuses are the HTTP status codes retried by default.
+var DefaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryError returns true if `err` occurred establishing a connection,
+// in which case the request was not sent and may be retried without the risk
+// of duplicating it.
+func DefaultRetryError(err error) bool {
+	var e *net.OpError
+	return errors.As(err, &e) && e.Op == "dial"
+}
+
+// RetryPolicy configures request retries with exponential backoff. Request
+// bodies are buffered so they may be replayed, and AWS requests are re-signed
+// on each attempt.
+type RetryPolicy struct {
+	MaxAttempts int                  // Maximum number of attempts, including the first
+	MinBackoff  time.Duration        // Backoff before the first retry
+	MaxBackoff  time.Duration        // Maximum backoff between attempts
+	Factor      float64              // Backoff multiplier applied per attempt, defaults to 2
+	Jitter      float64              // Jitter as a fraction of the backoff in [0, 1]
+	Statuses    []int                // Retryable status codes, defaults to DefaultRetryStatuses
+	RetryError  func(err error) bool // Retryable network errors, defaults to DefaultRetryError
+}
+
+// DefaultRetryPolicy returns a policy of 5 attempts backing off from 100ms to 10s.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 5,
+		MinBackoff:  100 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+		Factor:      2,
+		Jitter:      0.5,
+	}
+}
+
+// Backoff returns the backoff before retrying after `attempt`, starting at 1.
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	factor := p.Factor
+	if factor == 0 {
+		factor = 2
+	}
+
+	d := float64(p.MinBackoff) * math.Pow(factor, float64(attempt-1))
+
+	if max := float64(p.MaxBackoff); max > 0 && d > max {
+		d = max
+	}
+
+	if p.Jitter > 0 {
+		d -= d * p.Jitter * rand.Float64()
+	}
+
+	return time.Duration(d)
+}
+
+// retry returns the delay before the next attempt and true if the
+// response `res` or error `err` of `attempt` should be retried.
+func (p *RetryPolicy) retry(ctx context.Context, attempt int, res *http.Response, err error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return 0, false
+	}
+
+	if err != nil {
+		if !p.retryError(err) {
+			return 0, false
+		}
+
+		return p.Backoff(attempt), true
+	}
+
+	if !p.retryStatus(res.StatusCode) {
+		return 0, false
+	}
+
+	d := p.Backoff(attempt)
+
+	if after, ok := retryAfter(res.Header.Get("Retry-After"), time.Now()); ok && after > d {
+		d = after
+	}
+
+	return d, true
+}
+
+// retryError returns true if network error `err` may be retried or failed over.
+func (p *RetryPolicy) retryError(err error) bool {
+	if p == nil || p.RetryError == nil {
+		return DefaultRetryError(err)
+	}
+
+	return p.RetryError(err)
+}
+
+// retryStatus returns true if `status` should be retried.
+func (p *RetryPolicy) retryStatus(status int) bool {
+	statuses := p.Statuses
+	if statuses == nil {
+		statuses = DefaultRetryStatuses
+	}
+
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryAfter parses a Retry-After header value in seconds or as an HTTP date.
+func retryAfter(s string, now time.Time) (time.Duration, bool) {
+	if s == "" {
+		return 0, false
+	}
+
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(s); err == nil {
+		return t.Sub(now), true
+	}
+
+	return 0, false
+}
+
+// readBody reads `body` so that it may be replayed.
+func readBody(body io.Reader) ([]byte, error) {
+	if b, ok := body.(*bytes.Buffer); ok {
+		return b.Bytes(), nil
+	}
+
+	return ioutil.ReadAll(body)
+}
+
+// Sleep for `d` or until `ctx` is done, returning its error.
+func Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
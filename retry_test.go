@@ -0,0 +1,128 @@
+package elastic
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Retry(t *testing.T) {
+	var bodies []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+
+		if len(bodies) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	client.Retry = &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}
+
+	assert.NoError(t, client.Bulk(ioutil.NopCloser(strings.NewReader(docs))))
+	assert.Equal(t, []string{docs, docs, docs}, bodies)
+}
+
+func TestClient_Retry_exhausted(t *testing.T) {
+	var attempts int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	client.Retry = &RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}
+
+	err := client.RefreshAll()
+	assert.True(t, IsTooManyRequests(err), "too many requests")
+	assert.Equal(t, 2, attempts)
+}
+
+func TestClient_Retry_status(t *testing.T) {
+	var attempts int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	client.Retry = &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}
+
+	assert.True(t, IsNotFound(client.RefreshAll()), "not found")
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := &RetryPolicy{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, p.Backoff(1))
+	assert.Equal(t, 2*time.Second, p.Backoff(2))
+	assert.Equal(t, 4*time.Second, p.Backoff(3))
+	assert.Equal(t, 5*time.Second, p.Backoff(4))
+
+	p.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		d := p.Backoff(2)
+		assert.True(t, d > time.Second && d <= 2*time.Second, "jitter")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	d, ok := retryAfter("3", now)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, d)
+
+	d, ok = retryAfter("Fri, 01 Jan 2016 00:00:10 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Second, d)
+
+	_, ok = retryAfter("", now)
+	assert.False(t, ok)
+}
+
+func TestClient_Retry_error(t *testing.T) {
+	var attempts int32
+
+	// closes the connection after reading the request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	client.Retry = &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}
+
+	assert.Error(t, client.Bulk(strings.NewReader(docs)))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestClient_Retry_dialError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+
+	var attempts int
+
+	client := New(ts.URL)
+	client.Retry = &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}
+	client.OnRequest = func(RequestTrace) { attempts++ }
+
+	assert.Error(t, client.Bulk(strings.NewReader(docs)))
+	assert.Equal(t, 3, attempts)
+}
@@ -0,0 +1,230 @@
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ScrollIterator iterates the pages of a scrolled search. The scroll context
+// is cleared when the results are exhausted, on error, or on Close.
+type ScrollIterator struct {
+	client    *Client
+	ctx       context.Context
+	index     string
+	body      func() ([]byte, error)
+	keepAlive time.Duration
+	id        string
+	res       *SearchResult
+	err       error
+	done      bool
+}
+
+// Scroll returns an iterator over all results of search `body` against `index`,
+// keeping the scroll context alive for `keepAlive` between pages. Unlike Paginate
+// the `body` is the whole search body such as a query.SearchSource, not a query
+// clause, or nil to match all documents. For example:
+//
+//	it := client.Scroll(ctx, "pets", body, time.Minute)
+//	defer it.Close()
+//
+//	for it.Next() {
+//	  for _, hit := range it.Hits() {
+//	    ...
+//	  }
+//	}
+//
+//	if err := it.Err(); err != nil {
+//	  ...
+//	}
+func (c *Client) Scroll(ctx context.Context, index string, body interface{}, keepAlive time.Duration) *ScrollIterator {
+	return &ScrollIterator{
+		client:    c,
+		ctx:       ctx,
+		index:     index,
+		keepAlive: keepAlive,
+		body: func() ([]byte, error) {
+			if body == nil {
+				return []byte("{}"), nil
+			}
+			return json.Marshal(body)
+		},
+	}
+}
+
+// Next fetches the next page of hits, returning false when the results
+// are exhausted or an error occurs.
+func (s *ScrollIterator) Next() bool {
+	if s.done || s.err != nil {
+		return false
+	}
+
+	res := new(SearchResult)
+	keepAlive := keepAlive(s.keepAlive)
+
+	if s.id == "" {
+		b, err := s.body()
+		if err != nil {
+			s.err = err
+			return false
+		}
+
+		path := fmt.Sprintf("/_search?scroll=%s", keepAlive)
+		if s.index != "" {
+			path = fmt.Sprintf("/%s/_search?scroll=%s", s.index, keepAlive)
+		}
+
+		s.err = s.client.RequestContext(s.ctx, "POST", path, bytes.NewReader(b), res)
+	} else {
+		b, _ := json.Marshal(map[string]string{
+			"scroll":    keepAlive,
+			"scroll_id": s.id,
+		})
+		s.err = s.client.RequestContext(s.ctx, "POST", "/_search/scroll", bytes.NewReader(b), res)
+	}
+
+	if res.ScrollID != "" {
+		s.id = res.ScrollID
+	}
+
+	if s.err != nil {
+		s.Close()
+		return false
+	}
+
+	if len(res.Hits.Hits) == 0 {
+		s.Close()
+		return false
+	}
+
+	s.res = res
+	return true
+}
+
+// Hits returns the current page of hits.
+func (s *ScrollIterator) Hits() []*SearchHit {
+	if s.res == nil {
+		return nil
+	}
+
+	return s.res.Hits.Hits
+}
+
+// Result returns the current page's search result.
+func (s *ScrollIterator) Result() *SearchResult {
+	return s.res
+}
+
+// Err returns the error which stopped iteration, if any.
+func (s *ScrollIterator) Err() error {
+	return s.err
+}
+
+// Close clears the scroll context. It is safe to call more than once.
+func (s *ScrollIterator) Close() error {
+	s.done = true
+	s.res = nil
+
+	if s.id == "" {
+		return nil
+	}
+
+	b, _ := json.Marshal(map[string][]string{
+		"scroll_id": {s.id},
+	})
+
+	s.id = ""
+
+	// clear even when the iteration's context was cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := s.client.RequestContext(ctx, "DELETE", "/_search/scroll", bytes.NewReader(b), nil)
+	if IsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
+
+// ScrollSlices scrolls search `body` against `index` as `slices` sliced scrolls
+// in parallel, calling `fn` with each page of hits. Note that `fn` is called
+// concurrently. The first error returned from `fn` or a scroll stops all slices.
+func (c *Client) ScrollSlices(ctx context.Context, index string, body interface{}, keepAlive time.Duration, slices int, fn func([]*SearchHit) error) error {
+	if slices <= 0 {
+		return fmt.Errorf("elastic: expected at least 1 slice, got %d", slices)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var first error
+
+	fail := func(err error) {
+		once.Do(func() {
+			first = err
+			cancel()
+		})
+	}
+
+	for i := 0; i < slices; i++ {
+		it := c.Scroll(ctx, index, body, keepAlive)
+		if slices > 1 {
+			it.body = slicedBody(body, i, slices)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer it.Close()
+
+			for it.Next() {
+				if err := fn(it.Hits()); err != nil {
+					fail(err)
+					return
+				}
+			}
+
+			if err := it.Err(); err != nil {
+				fail(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+	return first
+}
+
+// slicedBody returns a body function for slice `id` of `max` of search `body`.
+func slicedBody(body interface{}, id, max int) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		var v map[string]interface{}
+
+		if body != nil {
+			b, err := json.Marshal(body)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := json.Unmarshal(b, &v); err != nil {
+				return nil, err
+			}
+		}
+
+		if v == nil {
+			v = make(map[string]interface{})
+		}
+
+		v["slice"] = map[string]int{
+			"id":  id,
+			"max": max,
+		}
+
+		return json.Marshal(v)
+	}
+}
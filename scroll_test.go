@@ -0,0 +1,125 @@
+package elastic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scrollServer returns a server responding with `pages` of hits for each scroll.
+func scrollServer(t *testing.T, pages int, cleared *[]string) *httptest.Server {
+	var mu sync.Mutex
+	page := make(map[string]int)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		b, _ := ioutil.ReadAll(r.Body)
+
+		var body struct {
+			ScrollID interface{} `json:"scroll_id"`
+			Slice    struct {
+				ID int `json:"id"`
+			} `json:"slice"`
+		}
+
+		assert.NoError(t, json.Unmarshal(b, &body))
+
+		switch {
+		case r.Method == "DELETE":
+			*cleared = append(*cleared, fmt.Sprint(body.ScrollID))
+			return
+		case r.URL.Path == "/pets/_search":
+			assert.Equal(t, "60000ms", r.URL.Query().Get("scroll"))
+			body.ScrollID = fmt.Sprintf("scroll-%d", body.Slice.ID)
+		case r.URL.Path != "/_search/scroll":
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+
+		id := body.ScrollID.(string)
+		n := page[id]
+		page[id]++
+
+		var hits []*SearchHit
+		if n < pages {
+			hits = append(hits, &SearchHit{ID: fmt.Sprintf("%s-%d", id, n), Source: json.RawMessage(`{}`)})
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"_scroll_id": id,
+			"hits":       map[string]interface{}{"hits": hits},
+		})
+	}))
+}
+
+func TestClient_Scroll(t *testing.T) {
+	var cleared []string
+	ts := scrollServer(t, 3, &cleared)
+	defer ts.Close()
+
+	it := New(ts.URL).Scroll(context.Background(), "pets", map[string]interface{}{"size": 1}, time.Minute)
+	defer it.Close()
+
+	var ids []string
+	for it.Next() {
+		for _, hit := range it.Hits() {
+			ids = append(ids, hit.ID)
+		}
+	}
+
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"scroll-0-0", "scroll-0-1", "scroll-0-2"}, ids)
+	assert.Equal(t, []string{"[scroll-0]"}, cleared)
+}
+
+func TestClient_ScrollSlices(t *testing.T) {
+	var cleared []string
+	ts := scrollServer(t, 2, &cleared)
+	defer ts.Close()
+
+	var mu sync.Mutex
+	var n int
+
+	err := New(ts.URL).ScrollSlices(context.Background(), "pets", nil, time.Minute, 3, func(hits []*SearchHit) error {
+		mu.Lock()
+		defer mu.Unlock()
+		n += len(hits)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+	assert.Len(t, cleared, 3)
+}
+
+func TestClient_Scroll_allIndices(t *testing.T) {
+	var uri string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uri = r.URL.RequestURI()
+		w.Write([]byte(`{ "hits": { "hits": [] } }`))
+	}))
+	defer ts.Close()
+
+	it := New(ts.URL).Scroll(context.Background(), "", nil, time.Minute)
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+	assert.Equal(t, "/_search?scroll=60000ms", uri)
+}
+
+func TestClient_ScrollSlices_invalid(t *testing.T) {
+	err := New("http://localhost:9200").ScrollSlices(context.Background(), "pets", nil, time.Minute, 0, func([]*SearchHit) error {
+		return nil
+	})
+
+	assert.EqualError(t, err, "elastic: expected at least 1 slice, got 0")
+}
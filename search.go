@@ -0,0 +1,144 @@
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// SearchResult for _search.
+type SearchResult struct {
+	Took     int64        `json:"took"`
+	TimedOut bool         `json:"timed_out"`
+	Shards   Shards       `json:"_shards"`
+	Hits     SearchHits   `json:"hits"`
+	Aggs     Aggregations `json:"aggregations,omitempty"`
+	ScrollID string       `json:"_scroll_id,omitempty"`
+	PITID    string       `json:"pit_id,omitempty"`
+}
+
+// Shards summary.
+type Shards struct {
+	Total      int            `json:"total"`
+	Successful int            `json:"successful"`
+	Skipped    int            `json:"skipped"`
+	Failed     int            `json:"failed"`
+	Failures   []ShardFailure `json:"failures,omitempty"`
+}
+
+// ShardFailure for a shard.
+type ShardFailure struct {
+	Shard  int        `json:"shard"`
+	Index  string     `json:"index"`
+	Node   string     `json:"node"`
+	Reason ErrorCause `json:"reason"`
+}
+
+// SearchHits for _search.
+type SearchHits struct {
+	Total    TotalHits    `json:"total"`
+	MaxScore *float64     `json:"max_score"`
+	Hits     []*SearchHit `json:"hits"`
+}
+
+// TotalHits is the total number of hits, which is a lower bound
+// when Relation is "gte".
+type TotalHits struct {
+	Value    int64  `json:"value"`
+	Relation string `json:"relation"`
+}
+
+// UnmarshalJSON implementation accepting the Elasticsearch 7 object
+// form as well as the plain number used by earlier versions.
+func (t *TotalHits) UnmarshalJSON(b []byte) error {
+	var n int64
+	if err := json.Unmarshal(b, &n); err == nil {
+		t.Value = n
+		t.Relation = "eq"
+		return nil
+	}
+
+	type total TotalHits
+	return json.Unmarshal(b, (*total)(t))
+}
+
+// SearchHit is a single hit.
+type SearchHit struct {
+	Index     string              `json:"_index"`
+	Type      string              `json:"_type,omitempty"`
+	ID        string              `json:"_id"`
+	Score     *float64            `json:"_score"`
+	Routing   string              `json:"_routing,omitempty"`
+	Source    json.RawMessage     `json:"_source,omitempty"`
+	Sort      []json.RawMessage   `json:"sort,omitempty"`
+	Highlight map[string][]string `json:"highlight,omitempty"`
+}
+
+// Aggregations are raw aggregation results by name. The typed accessors
+// return false when an aggregation is missing or fails to decode, for
+// example when it is of another type. Decode reports which.
+type Aggregations map[string]json.RawMessage
+
+// Hits decodes the hit sources of `res` as T. Hits without a source, such as
+// when _source is disabled, are left as the zero value.
+func Hits[T any](res *SearchResult) ([]T, error) {
+	v := make([]T, len(res.Hits.Hits))
+
+	for i, hit := range res.Hits.Hits {
+		if len(hit.Source) == 0 {
+			continue
+		}
+
+		if err := json.Unmarshal(hit.Source, &v[i]); err != nil {
+			return nil, fmt.Errorf("elastic: decoding hit %q: %w", hit.ID, err)
+		}
+	}
+
+	return v, nil
+}
+
+// Search performs search request `r`, with its Index, Body and parameters.
+func (c *Client) Search(ctx context.Context, r SearchRequest) (*SearchResult, error) {
+	body := []byte("{}")
+
+	if r.Body != nil {
+		b, err := json.Marshal(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	params := url.Values{}
+
+	if r.Routing != "" {
+		params.Set("routing", r.Routing)
+	}
+
+	if r.Preference != "" {
+		params.Set("preference", r.Preference)
+	}
+
+	if r.SearchType != "" {
+		params.Set("search_type", r.SearchType)
+	}
+
+	if r.RequestCache != nil {
+		params.Set("request_cache", strconv.FormatBool(*r.RequestCache))
+	}
+
+	path := "/_search"
+	if r.Index != "" {
+		path = fmt.Sprintf("/%s/_search", r.Index)
+	}
+
+	res := new(SearchResult)
+	if err := c.RequestContext(ctx, "POST", withParams(path, params), bytes.NewReader(body), res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
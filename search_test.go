@@ -0,0 +1,92 @@
+package elastic
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pet struct {
+	Name    string `json:"name"`
+	Species string `json:"species"`
+}
+
+var searchResult = `{
+  "took": 5,
+  "timed_out": false,
+  "_shards": { "total": 1, "successful": 1, "skipped": 0, "failed": 0 },
+  "hits": {
+    "total": { "value": 2, "relation": "eq" },
+    "max_score": 1.3,
+    "hits": [
+      { "_index": "pets", "_id": "1", "_score": 1.3, "_source": { "name": "Tobi", "species": "ferret" }, "highlight": { "name": ["<em>Tobi</em>"] } },
+      { "_index": "pets", "_id": "2", "_score": 1.1, "_source": { "name": "Loki", "species": "ferret" }, "sort": [1.1, "2"] }
+    ]
+  },
+  "aggregations": {
+    "species": { "buckets": [] }
+  }
+}`
+
+func TestSearchResult(t *testing.T) {
+	var res SearchResult
+	assert.NoError(t, json.Unmarshal([]byte(searchResult), &res))
+
+	assert.Equal(t, int64(5), res.Took)
+	assert.Equal(t, 1, res.Shards.Successful)
+	assert.Equal(t, TotalHits{Value: 2, Relation: "eq"}, res.Hits.Total)
+	assert.Equal(t, 1.3, *res.Hits.MaxScore)
+	assert.Equal(t, "1", res.Hits.Hits[0].ID)
+	assert.Equal(t, []string{"<em>Tobi</em>"}, res.Hits.Hits[0].Highlight["name"])
+	assert.Equal(t, []json.RawMessage{json.RawMessage(`1.1`), json.RawMessage(`"2"`)}, res.Hits.Hits[1].Sort)
+	assert.JSONEq(t, `{ "buckets": [] }`, string(res.Aggs["species"]))
+
+	pets, err := Hits[pet](&res)
+	assert.NoError(t, err)
+	assert.Equal(t, []pet{{"Tobi", "ferret"}, {"Loki", "ferret"}}, pets)
+}
+
+func TestHits_noSource(t *testing.T) {
+	var res SearchResult
+	assert.NoError(t, json.Unmarshal([]byte(`{ "hits": { "hits": [ { "_id": "1" }, { "_id": "2", "_source": { "name": "Loki" } } ] } }`), &res))
+
+	pets, err := Hits[pet](&res)
+	assert.NoError(t, err)
+	assert.Equal(t, []pet{{}, {Name: "Loki"}}, pets)
+}
+
+func TestTotalHits(t *testing.T) {
+	var res SearchResult
+	assert.NoError(t, json.Unmarshal([]byte(`{ "hits": { "total": 15, "hits": [] } }`), &res))
+	assert.Equal(t, TotalHits{Value: 15, Relation: "eq"}, res.Hits.Total)
+}
+
+func TestClient_Search(t *testing.T) {
+	var uri, body string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uri = r.URL.RequestURI()
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(searchResult))
+	}))
+	defer ts.Close()
+
+	cache := true
+
+	res, err := New(ts.URL).Search(context.Background(), SearchRequest{
+		Index:        "pets",
+		Body:         map[string]interface{}{"size": 0, "track_total_hits": true},
+		RequestCache: &cache,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/pets/_search?request_cache=true", uri)
+	assert.Equal(t, `{"size":0,"track_total_hits":true}`, body)
+	assert.NotNil(t, res)
+}
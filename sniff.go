@@ -0,0 +1,218 @@
+package elastic
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrSniffingUnsupported is returned when sniffing an AWS hosted domain,
+// which does not expose its nodes.
+var ErrSniffingUnsupported = errors.New("elastic: sniffing is not supported for AWS hosted domains")
+
+// ErrNoNodes is returned when sniffing finds no eligible nodes.
+var ErrNoNodes = errors.New("elastic: no nodes found")
+
+// NodeInfo is a node discovered by sniffing.
+type NodeInfo struct {
+	ID      string   // Node ID
+	Name    string   // Node name
+	Roles   []string // Node roles such as "master" and "data"
+	Address string   // HTTP publish address such as "10.0.0.1:9200"
+}
+
+// MasterOnly returns true if the node is a dedicated master.
+func (n NodeInfo) MasterOnly() bool {
+	master := false
+
+	for _, r := range n.Roles {
+		switch r {
+		case "master":
+			master = true
+		case "voting_only":
+		default:
+			return false
+		}
+	}
+
+	return master
+}
+
+// SniffOptions configures sniffing.
+type SniffOptions struct {
+	Interval          time.Duration         // Interval between sniffs, zero sniffs once
+	IncludeMasterOnly bool                  // Include dedicated master nodes
+	Scheme            string                // Scheme of node urls, defaults to that of the client's nodes, or "http"
+	URL               func(NodeInfo) string // URL override for nodes, for example behind Docker or NAT
+	OnError           func(error)           // Called with background sniffing errors
+}
+
+// nodeURL returns the url of node `n`.
+func (o *SniffOptions) nodeURL(n NodeInfo) string {
+	if o.URL != nil {
+		return o.URL(n)
+	}
+
+	scheme := o.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	return scheme + "://" + n.Address
+}
+
+// nodesResponse for _nodes/http.
+type nodesResponse struct {
+	Nodes map[string]struct {
+		Name       string            `json:"name"`
+		Roles      []string          `json:"roles"`
+		Attributes map[string]string `json:"attributes"`
+		HTTP       struct {
+			PublishAddress string `json:"publish_address"`
+		} `json:"http"`
+	} `json:"nodes"`
+}
+
+// SniffNodes returns the cluster's nodes with HTTP enabled.
+func (c *Client) SniffNodes(ctx context.Context) ([]NodeInfo, error) {
+	if c.awsCredentials != nil {
+		return nil, ErrSniffingUnsupported
+	}
+
+	var res nodesResponse
+	if err := c.RequestContext(ctx, "GET", "/_nodes/http", nil, &res); err != nil {
+		return nil, err
+	}
+
+	var nodes []NodeInfo
+
+	for id, n := range res.Nodes {
+		if n.HTTP.PublishAddress == "" {
+			continue
+		}
+
+		roles := n.Roles
+
+		// Elasticsearch 2.x
+		if roles == nil {
+			roles = legacyRoles(n.Attributes)
+		}
+
+		nodes = append(nodes, NodeInfo{
+			ID:      id,
+			Name:    n.Name,
+			Roles:   roles,
+			Address: publishAddress(n.HTTP.PublishAddress),
+		})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	return nodes, nil
+}
+
+// Sniff discovers the cluster's nodes and distributes requests across them.
+func (c *Client) Sniff(ctx context.Context, opts SniffOptions) error {
+	nodes, err := c.SniffNodes(ctx)
+	if err != nil {
+		return err
+	}
+
+	if opts.Scheme == "" {
+		opts.Scheme = c.scheme()
+	}
+
+	var urls []string
+
+	for _, n := range nodes {
+		if n.MasterOnly() && !opts.IncludeMasterOnly {
+			continue
+		}
+
+		urls = append(urls, opts.nodeURL(n))
+	}
+
+	if len(urls) == 0 {
+		return ErrNoNodes
+	}
+
+	c.setNodes(urls)
+	return nil
+}
+
+// StartSniffing sniffs the cluster's nodes, and then again on the
+// configured interval until `ctx` is done. Sniffing is not supported
+// for AWS hosted domains.
+func (c *Client) StartSniffing(ctx context.Context, opts SniffOptions) error {
+	if err := c.Sniff(ctx, opts); err != nil {
+		return err
+	}
+
+	if opts.Interval == 0 {
+		return nil
+	}
+
+	go func() {
+		t := time.NewTicker(opts.Interval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if err := c.Sniff(ctx, opts); err != nil && opts.OnError != nil && ctx.Err() == nil {
+					opts.OnError(err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// scheme returns the scheme of the client's nodes, or an empty string.
+func (c *Client) scheme() string {
+	for _, s := range c.Nodes() {
+		if u, err := url.Parse(s); err == nil && u.Scheme != "" {
+			return u.Scheme
+		}
+	}
+
+	return ""
+}
+
+// legacyRoles returns the roles of Elasticsearch 2.x node `attrs`.
+func legacyRoles(attrs map[string]string) (roles []string) {
+	if attrs["master"] != "false" {
+		roles = append(roles, "master")
+	}
+
+	if attrs["data"] != "false" {
+		roles = append(roles, "data")
+	}
+
+	if attrs["client"] == "true" {
+		roles = append(roles, "client")
+	}
+
+	return
+}
+
+// publishAddress normalizes publish address `s`, which may be
+// of the form "inet[/10.0.0.1:9200]" or "host/10.0.0.1:9200".
+func publishAddress(s string) string {
+	s = strings.TrimPrefix(s, "inet[")
+	s = strings.TrimSuffix(s, "]")
+
+	if i := strings.Index(s, "/"); i != -1 {
+		s = s[i+1:]
+	}
+
+	return s
+}
@@ -0,0 +1,118 @@
+package elastic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var nodesHTTP = `{
+  "cluster_name": "elasticsearch",
+  "nodes": {
+    "a": {
+      "name": "data-1",
+      "roles": ["data", "ingest", "master"],
+      "http": { "publish_address": "data-1/10.0.0.1:9200" }
+    },
+    "b": {
+      "name": "master-1",
+      "roles": ["master"],
+      "http": { "publish_address": "10.0.0.2:9200" }
+    },
+    "c": {
+      "name": "data-2",
+      "roles": ["data"],
+      "http": { "publish_address": "10.0.0.3:9200" }
+    }
+  }
+}`
+
+func TestClient_Sniff(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_nodes/http", r.URL.Path)
+		fmt.Fprint(w, nodesHTTP)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	assert.NoError(t, client.Sniff(context.Background(), SniffOptions{}))
+	assert.Equal(t, []string{"http://10.0.0.1:9200", "http://10.0.0.3:9200"}, client.Nodes())
+}
+
+func TestClient_Sniff_options(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, nodesHTTP)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	opts := SniffOptions{
+		IncludeMasterOnly: true,
+		URL: func(n NodeInfo) string {
+			return "https://" + n.Name + ".local:" + strings.Split(n.Address, ":")[1]
+		},
+	}
+
+	assert.NoError(t, client.Sniff(context.Background(), opts))
+	assert.Equal(t, []string{"https://data-1.local:9200", "https://master-1.local:9200", "https://data-2.local:9200"}, client.Nodes())
+}
+
+func TestClient_Sniff_concurrent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, nodesHTTP)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	opts := SniffOptions{URL: func(NodeInfo) string { return ts.URL }}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, client.Sniff(context.Background(), opts))
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		_, err := client.SniffNodes(context.Background())
+		assert.NoError(t, err)
+	}
+
+	wg.Wait()
+	assert.Equal(t, []string{ts.URL, ts.URL}, client.Nodes())
+}
+
+func TestClient_Sniff_scheme(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, nodesHTTP)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	client.HTTPClient = ts.Client()
+
+	assert.NoError(t, client.Sniff(context.Background(), SniffOptions{}))
+	assert.Equal(t, []string{"https://10.0.0.1:9200", "https://10.0.0.3:9200"}, client.Nodes())
+}
+
+func TestClient_Sniff_aws(t *testing.T) {
+	client := New("https://search-logs.us-west-2.es.amazonaws.com")
+	client.SetAWSCredentials(AWSCredentials{})
+	assert.Equal(t, ErrSniffingUnsupported, client.Sniff(context.Background(), SniffOptions{}))
+}
+
+func TestPublishAddress(t *testing.T) {
+	assert.Equal(t, "10.0.0.1:9200", publishAddress("10.0.0.1:9200"))
+	assert.Equal(t, "10.0.0.1:9200", publishAddress("host/10.0.0.1:9200"))
+	assert.Equal(t, "10.0.0.1:9200", publishAddress("inet[/10.0.0.1:9200]"))
+}